@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/elusiv0/oz_task/internal/config"
+)
+
+// checkConfig builds everything serve depends on without starting the server,
+// so a broken config or an outdated schema is found before the deploy.
+func checkConfig(ctx context.Context, config *config.Config, logger *slog.Logger, args []string) error {
+	if _, err := newVerifier(config); err != nil {
+		return err
+	}
+	fmt.Println("auth: ok")
+
+	storage, err := newStorage(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+	if storage.pg != nil {
+		fmt.Println("postgres: connected, schema is up to date")
+	}
+
+	fmt.Printf("config is valid: db=%s, port=%s\n", config.App.Db, config.Http.Port)
+
+	return nil
+}
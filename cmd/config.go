@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/elusiv0/oz_task/internal/config"
+)
+
+// configCmd prints the effective config, it is printed even when invalid and the validation errors follow.
+func configCmd(ctx context.Context, config *config.Config, logger *slog.Logger, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return fmt.Errorf("expected print, got %v", args)
+	}
+	if err := config.Print(os.Stdout); err != nil {
+		return err
+	}
+
+	return config.Validate()
+}
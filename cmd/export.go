@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+
+	"github.com/elusiv0/oz_task/internal/config"
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+// exportPageSize is the number of users and posts read by a single repo call.
+const exportPageSize = 100
+
+// dump is the format of export and import, the records reference each other by the exported ids.
+// Comments follow their parents, so they can be inserted in the order of the dump.
+type dump struct {
+	Users    []*dto.User    `json:"users"`
+	Posts    []*dto.Post    `json:"posts"`
+	Comments []*dto.Comment `json:"comments"`
+}
+
+// export writes every user, post and visible comment as JSON, oldest first.
+func export(ctx context.Context, config *config.Config, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "", "output file, stdout by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requirePostgres(config, "export"); err != nil {
+		return err
+	}
+	storage, err := newStorage(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	data := dump{}
+	var afterUser *int
+	for {
+		usersResp, err := storage.userRepo.GetMany(ctx, dto.GetUsersRequest{First: exportPageSize, After: afterUser})
+		if err != nil && !isNoContent(err) {
+			return fmt.Errorf("get users: %w", err)
+		}
+		hasNext := len(usersResp) > exportPageSize
+		if hasNext {
+			usersResp = usersResp[:exportPageSize]
+		}
+		data.Users = append(data.Users, usersResp...)
+		if !hasNext {
+			break
+		}
+		afterUser = &usersResp[len(usersResp)-1].ID
+	}
+	// users are paged newest first only
+	for i, j := 0, len(data.Users)-1; i < j; i, j = i+1, j-1 {
+		data.Users[i], data.Users[j] = data.Users[j], data.Users[i]
+	}
+
+	var afterPost *dto.Cursor
+	for {
+		postsResp, err := storage.postRepo.GetMany(ctx, dto.GetPostsRequest{
+			Page:  dto.Page{First: exportPageSize, After: afterPost},
+			Order: dto.OrderOldest,
+		})
+		if err != nil && !isNoContent(err) {
+			return fmt.Errorf("get posts: %w", err)
+		}
+		hasNext := len(postsResp) > exportPageSize
+		if hasNext {
+			postsResp = postsResp[:exportPageSize]
+		}
+		data.Posts = append(data.Posts, postsResp...)
+		if !hasNext {
+			break
+		}
+		afterPost = postsResp[len(postsResp)-1].Cursor
+	}
+
+	for _, post := range data.Posts {
+		nodesResp, err := storage.commentRepo.Thread(ctx, dto.ThreadRequest{
+			PostId:   &post.ID,
+			MaxDepth: math.MaxInt32,
+			MaxNodes: math.MaxInt32,
+		})
+		if err != nil && !isNoContent(err) {
+			return fmt.Errorf("get comments of post %d: %w", post.ID, err)
+		}
+		for _, node := range nodesResp {
+			data.Comments = append(data.Comments, node.Comment)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("create output: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("encode dump: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "exported %d users, %d posts, %d comments\n", len(data.Users), len(data.Posts), len(data.Comments))
+
+	return nil
+}
+
+// importDump inserts the records of the dump through the services, every record gets a new id.
+// Timestamps are not kept, deleted comments are imported as tombstones.
+func importDump(ctx context.Context, config *config.Config, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	input := flags.String("i", "", "input file, stdin by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requirePostgres(config, "import"); err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return fmt.Errorf("open input: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+	data := dump{}
+	if err := json.NewDecoder(in).Decode(&data); err != nil {
+		return fmt.Errorf("decode dump: %w", err)
+	}
+
+	storage, err := newStorage(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+	services := newServices(config, storage, logger)
+
+	usersId := make(map[int]int, len(data.Users))
+	for _, user := range data.Users {
+		userResp, err := services.user.Insert(ctx, dto.NewUser{Username: user.Username})
+		if err != nil {
+			return fmt.Errorf("insert user %d: %w", user.ID, err)
+		}
+		usersId[user.ID] = userResp.ID
+	}
+
+	postsId := make(map[int]int, len(data.Posts))
+	for _, post := range data.Posts {
+		postResp, err := services.post.Insert(ctx, dto.NewPost{
+			Title:    post.Title,
+			Text:     post.Text,
+			Closed:   post.Closed && post.ClosedBy == nil,
+			AuthorID: mapId(usersId, post.AuthorID),
+		})
+		if err != nil {
+			return fmt.Errorf("insert post %d: %w", post.ID, err)
+		}
+		if closedBy := mapId(usersId, post.ClosedBy); post.Closed && closedBy != nil {
+			if _, err := services.post.Close(ctx, postResp.ID, *closedBy); err != nil {
+				return fmt.Errorf("close post %d: %w", post.ID, err)
+			}
+		}
+		postsId[post.ID] = postResp.ID
+	}
+
+	commentsId := make(map[int]int, len(data.Comments))
+	for _, comment := range data.Comments {
+		postId, ok := postsId[comment.ArticleID]
+		if !ok {
+			return fmt.Errorf("comment %d references unknown post %d", comment.ID, comment.ArticleID)
+		}
+		commentResp, err := services.comment.Insert(ctx, dto.NewComment{
+			Text:      comment.Text,
+			ArticleID: postId,
+			ParentID:  mapId(commentsId, comment.ParentID),
+			AuthorID:  mapId(usersId, comment.AuthorID),
+		})
+		if err != nil {
+			return fmt.Errorf("insert comment %d: %w", comment.ID, err)
+		}
+		if comment.Deleted {
+			if _, err := services.comment.Delete(ctx, commentResp.ID); err != nil {
+				return fmt.Errorf("delete comment %d: %w", comment.ID, err)
+			}
+		}
+		commentsId[comment.ID] = commentResp.ID
+	}
+
+	fmt.Printf("imported %d users, %d posts, %d comments\n", len(usersId), len(postsId), len(commentsId))
+
+	return nil
+}
+
+// mapId returns the new id of the exported record, nil stays nil.
+func mapId(ids map[int]int, id *int) *int {
+	if id == nil {
+		return nil
+	}
+	newId, ok := ids[*id]
+	if !ok {
+		return nil
+	}
+
+	return &newId
+}
+
+// isNoContent reports whether the repo found nothing, which is not a failure for the maintenance commands.
+func isNoContent(err error) bool {
+	var cErr *dto.CustomError
+	return errors.As(err, &cErr) && cErr.GetStatus() == http.StatusNoContent
+}
@@ -2,117 +2,114 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
-	"net/http"
+	"log/slog"
+	"os"
 
-	"github.com/99designs/gqlgen/graphql"
-	"github.com/elusiv0/oz_task/internal/app"
 	"github.com/elusiv0/oz_task/internal/config"
-	"github.com/elusiv0/oz_task/internal/dto"
-	"github.com/elusiv0/oz_task/internal/graph"
-	resolver "github.com/elusiv0/oz_task/internal/graph/resolver"
-	"github.com/elusiv0/oz_task/internal/repo"
-	imCommentRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/comment"
-	imPostRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/post"
-	pgCommentRepo "github.com/elusiv0/oz_task/internal/repo/postgres/comment"
-	pgPostRepo "github.com/elusiv0/oz_task/internal/repo/postgres/post"
-	"github.com/elusiv0/oz_task/internal/router"
-	commentService "github.com/elusiv0/oz_task/internal/service/comment"
-	postService "github.com/elusiv0/oz_task/internal/service/post"
-	"github.com/elusiv0/oz_task/pkg/httpserver"
 	"github.com/elusiv0/oz_task/pkg/logger"
-	"github.com/elusiv0/oz_task/pkg/postgres"
 	"github.com/joho/godotenv"
 )
 
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, config *config.Config, logger *slog.Logger, args []string) error
+	// rawConfig commands get the config before the validation, so they can show a broken one
+	rawConfig bool
+}
+
+var commands = []command{
+	{name: "serve", usage: "run the GraphQL server, the default command", run: serve},
+	{name: "migrate", usage: "up|down|status - manage schema migrations", run: migrate},
+	{name: "seed", usage: "[-users n] [-posts n] [-comments n] - fill the db with generated data", run: seed},
+	{name: "export", usage: "[-o file] - dump users, posts and comments as JSON", run: export},
+	{name: "import", usage: "[-i file] - load a dump made by export", run: importDump},
+	{name: "check-config", usage: "validate the config and the connections it describes", run: checkConfig},
+	{name: "config", usage: "print - show the effective config with secrets redacted", run: configCmd, rawConfig: true},
+}
+
 func main() {
-	//load env variables
-	if err := godotenv.Load(".env"); err != nil {
+	//load env variables, the file is optional since the env may be set by the environment itself
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		log.Fatal("error with load env variables " + err.Error())
 	}
 
 	//building config
-	config, err := config.NewConfig()
+	config, args, err := config.NewConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		usage()
+		return
+	}
 	if err != nil {
 		log.Fatal("error with get config " + err.Error())
 	}
 
-	//building logger
-	logger := logger.New("local")
-
-	//building repo
-	var postRepo repo.PostRepo
-	var commentRepo repo.CommentRepo
-	if config.App.Db == "postgres" {
-		pg, err := postgres.New(
-			postgres.NewConnectionConfig(
-				config.Postgres.Host,
-				config.Postgres.Port,
-				config.Postgres.User,
-				config.Postgres.Password,
-				config.Postgres.Name,
-			),
-			logger,
-			postgres.ConnAttempts(config.Postgres.ConnectionAttempts),
-			postgres.ConnTimeout(config.Postgres.ConnectionTimeout),
-			postgres.MaxPoolSz(config.Postgres.MaxPoolSz),
-		)
-		if err != nil {
-			log.Fatal("error with set up pg connection " + err.Error())
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	var cmd *command
+	for idx := range commands {
+		if commands[idx].name == name {
+			cmd = &commands[idx]
 		}
+	}
+	if cmd == nil {
+		usage()
+		if name != "help" {
+			os.Exit(2)
+		}
+		return
+	}
 
-		postRepo = pgPostRepo.New(pg, logger)
-		commentRepo = pgCommentRepo.New(pg, logger)
-	} else {
-		postRepo = imPostRepo.New(logger)
-		commentRepo = imCommentRepo.New(logger)
+	if !cmd.rawConfig {
+		if err := config.Validate(); err != nil {
+			log.Fatal("error with validate config " + err.Error())
+		}
 	}
 
-	//building service
-	commentService := commentService.New(commentRepo, logger)
-	postService := postService.New(postRepo, logger)
+	//building logger
+	logger, err := newLogger(config)
+	if err != nil {
+		if !cmd.rawConfig {
+			log.Fatal("error with build logger " + err.Error())
+		}
+		logger = slog.Default()
+	}
 
-	//building gql
-	resolver := resolver.NewResolver(commentService, postService, logger)
-	gConfig := graph.Config{
-		Resolvers: resolver,
+	if err := cmd.run(context.Background(), config, logger, args); err != nil {
+		log.Fatal("error with " + cmd.name + ": " + err.Error())
 	}
-	countComplexity := func(childComplexity int, first, after *int) int {
-		return *first * childComplexity
+}
+func newLogger(config *config.Config) (*slog.Logger, error) {
+	opts := []logger.Option{
+		logger.Output(config.Log.Output),
+		logger.FileRotation(config.Log.FileMaxSizeMB, config.Log.FileMaxBackups, config.Log.FileMaxAgeDays),
+		logger.Sampling(config.Log.SamplingFirst, config.Log.SamplingThereafter, config.Log.SamplingTick),
 	}
-	gConfig.Complexity.Post.Comments = countComplexity
-	gConfig.Complexity.Query.Posts = countComplexity
-	gConfig.Complexity.Comment.Comments = countComplexity
-	gConfig.Directives.Length = func(ctx context.Context, obj interface{}, next graphql.Resolver, max int) (res interface{}, err error) {
-		commentInput := obj.(map[string]any)
-		commentText := commentInput["text"].(string)
-		lenT := len([]rune(commentText))
-		if lenT > max {
-			return nil, dto.NewCustomError(dto.ErrInfo{
-				ErrorMessage: fmt.Sprintf("length %d of text is more than max length of text %d", lenT, max),
-				StatusCode:   http.StatusForbidden,
-			}, nil)
+	if config.Log.Level != "" {
+		level, err := logger.ParseLevel(config.Log.Level)
+		if err != nil {
+			return nil, err
 		}
-		return next(ctx)
+		opts = append(opts, logger.Level(level))
+	}
+	if config.Log.Format != "" {
+		opts = append(opts, logger.Format(config.Log.Format))
 	}
 
-	//building router
-	router := router.InitRoutes(logger, gConfig, commentService)
-
-	//building httpserver
-	httpserver := httpserver.New(
-		router,
-		httpserver.Port(config.Http.Port),
-		httpserver.ReadTimeout(config.Http.ReadTimeout),
-		httpserver.ShutdownTimeout(config.Http.ShutdownTimeout),
-	)
-
-	//building app
-	app := app.New(httpserver, logger)
+	return logger.New(config.App.Env, opts...)
+}
 
-	logger.Info("Starting app on on port" + config.Http.Port + "...")
-	if err := app.Run(); err != nil {
-		log.Fatal("error with starting app" + err.Error())
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s [-config file] [-section.key value]... <command> [args]\n\ncommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-13s %s\n", cmd.name, cmd.usage)
 	}
+	fmt.Fprintf(os.Stderr, "\nevery config key can be set by a flag, e.g. -http.port :8080, see config print for the keys\n")
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/elusiv0/oz_task/internal/config"
+	"github.com/elusiv0/oz_task/internal/migrations"
+)
+
+const migrateUsage = "usage: migrate up|down|status"
+
+// migrate applies or rolls back the schema migrations, the schema may be behind here.
+func migrate(ctx context.Context, config *config.Config, logger *slog.Logger, args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+	if err := requirePostgres(config, "migrate"); err != nil {
+		return err
+	}
+	pg, err := newPostgres(config, logger)
+	if err != nil {
+		return fmt.Errorf("set up pg connection: %w", err)
+	}
+	defer pg.PgxPool.Close()
+	migrator, err := migrations.New(pg, logger)
+	if err != nil {
+		return fmt.Errorf("load migrations: %w", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			fmt.Println("no migrations to roll back")
+			return nil
+		}
+		fmt.Printf("rolled back %04d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statusResp, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statusResp {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied at " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+
+	"github.com/elusiv0/oz_task/internal/config"
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+// seed fills the db with generated users, posts and comments through the services,
+// so the generated data passes the same validation as the one created by clients.
+func seed(ctx context.Context, config *config.Config, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	usersCount := flags.Int("users", 5, "number of users")
+	postsCount := flags.Int("posts", 10, "number of posts")
+	commentsCount := flags.Int("comments", 50, "number of comments")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *usersCount < 1 {
+		return fmt.Errorf("seed requires at least one user")
+	}
+	if err := requirePostgres(config, "seed"); err != nil {
+		return err
+	}
+	storage, err := newStorage(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+	services := newServices(config, storage, logger)
+
+	var usersId []int
+	for idx := 0; idx < *usersCount; idx++ {
+		user, err := services.user.Insert(ctx, dto.NewUser{
+			Username: fmt.Sprintf("user_%d_%d", idx+1, rand.Intn(1_000_000)),
+		})
+		if err != nil {
+			return fmt.Errorf("insert user: %w", err)
+		}
+		usersId = append(usersId, user.ID)
+	}
+
+	var postsId []int
+	for idx := 0; idx < *postsCount; idx++ {
+		authorId := usersId[rand.Intn(len(usersId))]
+		post, err := services.post.Insert(ctx, dto.NewPost{
+			Title:    fmt.Sprintf("Post %d", idx+1),
+			Text:     fmt.Sprintf("Generated post number %d", idx+1),
+			AuthorID: &authorId,
+		})
+		if err != nil {
+			return fmt.Errorf("insert post: %w", err)
+		}
+		postsId = append(postsId, post.ID)
+	}
+
+	// every comment answers a random comment of the same post, half of them start a new thread
+	byPost := make(map[int][]*dto.Comment)
+	commentsInserted := 0
+	for idx := 0; idx < *commentsCount && len(postsId) > 0; idx++ {
+		postId := postsId[rand.Intn(len(postsId))]
+		authorId := usersId[rand.Intn(len(usersId))]
+		newComment := dto.NewComment{
+			Text:      fmt.Sprintf("Generated comment number %d", idx+1),
+			ArticleID: postId,
+			AuthorID:  &authorId,
+		}
+		if comments := byPost[postId]; len(comments) > 0 && rand.Intn(2) == 0 {
+			parent := comments[rand.Intn(len(comments))]
+			if parent.Depth < config.App.MaxCommentDepth {
+				newComment.ParentID = &parent.ID
+			}
+		}
+		comment, err := services.comment.Insert(ctx, newComment)
+		if err != nil {
+			return fmt.Errorf("insert comment: %w", err)
+		}
+		byPost[postId] = append(byPost[postId], comment)
+		commentsInserted++
+	}
+
+	fmt.Printf("seeded %d users, %d posts, %d comments\n", len(usersId), len(postsId), commentsInserted)
+
+	return nil
+}
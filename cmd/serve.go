@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/elusiv0/oz_task/internal/app"
+	"github.com/elusiv0/oz_task/internal/config"
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/graph"
+	"github.com/elusiv0/oz_task/internal/graph/complexity"
+	"github.com/elusiv0/oz_task/internal/graph/directive"
+	resolver "github.com/elusiv0/oz_task/internal/graph/resolver"
+	"github.com/elusiv0/oz_task/internal/metrics"
+	"github.com/elusiv0/oz_task/internal/middleware"
+	"github.com/elusiv0/oz_task/internal/router"
+	"github.com/elusiv0/oz_task/pkg/auth"
+	"github.com/elusiv0/oz_task/pkg/drain"
+	"github.com/elusiv0/oz_task/pkg/httpserver"
+	"github.com/elusiv0/oz_task/pkg/ratelimit"
+	"github.com/elusiv0/oz_task/pkg/tracing"
+)
+
+// serve runs the GraphQL server, it is the default command.
+func serve(ctx context.Context, config *config.Config, logger *slog.Logger, args []string) error {
+	//building tracing
+	shutdownTracing, err := newTracing(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+
+	//building repo
+	storage, err := newStorage(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	// the pool is closed by the app on shutdown
+
+	//building service
+	services := newServices(config, storage, logger)
+
+	//building metrics
+	metrics := metrics.New(config.Metrics.KnownOperations()...)
+	if storage.pg != nil {
+		if err := metrics.RegisterPool(storage.pg.PgxPool); err != nil {
+			return fmt.Errorf("register pool metrics: %w", err)
+		}
+	}
+
+	//building gql
+	resolver := resolver.NewResolver(services.comment, services.post, services.user, services.search, metrics, logger)
+	gConfig := graph.Config{
+		Resolvers: resolver,
+	}
+	complexity.Apply(&gConfig.Complexity)
+	gConfig.Directives.Length = func(ctx context.Context, obj interface{}, next graphql.Resolver, max int) (res interface{}, err error) {
+		commentInput := obj.(map[string]any)
+		commentText := commentInput["text"].(string)
+		lenT := len([]rune(commentText))
+		if lenT > max {
+			return nil, dto.NewCustomError(dto.ErrInfo{
+				ErrorMessage: fmt.Sprintf("length %d of text is more than max length of text %d", lenT, max),
+				StatusCode:   http.StatusForbidden,
+			}, nil)
+		}
+		return next(ctx)
+	}
+	gConfig.Directives.Auth = directive.Auth()
+	gConfig.Directives.Owner = directive.Owner(services.post, services.comment)
+
+	//building auth
+	verifier, err := newVerifier(config)
+	if err != nil {
+		return err
+	}
+
+	//building router
+	draining := drain.New()
+	router, err := router.InitRoutes(logger, gConfig, services.comment, services.post, services.user, verifier, storage.pg, metrics, newRateLimits(config), config.Graphql, config.Http.Proxies(), draining)
+	if err != nil {
+		return err
+	}
+
+	//building httpserver
+	httpserver := httpserver.New(
+		router,
+		httpserver.Port(config.Http.Port),
+		httpserver.ReadTimeout(config.Http.ReadTimeout),
+		httpserver.ShutdownTimeout(config.Http.ShutdownTimeout),
+	)
+
+	//building app
+	app := app.New(
+		httpserver,
+		logger,
+		app.Drain(draining),
+		app.ShutdownTimeout(config.Http.ShutdownTimeout),
+		app.OnShutdown(storage.Close),
+		app.OnShutdown(shutdownTracing),
+	)
+
+	logger.Info("Starting app on on port" + config.Http.Port + "...")
+	if err := app.Run(); err != nil {
+		return fmt.Errorf("starting app: %w", err)
+	}
+
+	return nil
+}
+
+// newTracing installs the tracer provider when the export is enabled and returns the hook flushing it on shutdown.
+func newTracing(ctx context.Context, config *config.Config, logger *slog.Logger) (func(), error) {
+	if !config.Tracing.Enabled {
+		return func() {}, nil
+	}
+	tracing, err := tracing.New(ctx,
+		tracing.Endpoint(config.Tracing.Endpoint),
+		tracing.Insecure(config.Tracing.Insecure),
+		tracing.SampleRatio(config.Tracing.SampleRatio),
+		tracing.Environment(config.App.Env),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("set up tracing: %w", err)
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Http.ShutdownTimeout)
+		defer cancel()
+		if err := tracing.Shutdown(ctx); err != nil {
+			logger.Error("error with shutdown tracing: " + err.Error())
+		}
+	}, nil
+}
+
+// newRateLimits returns nil when the rate limiting is disabled.
+func newRateLimits(config *config.Config) *middleware.RateLimits {
+	if !config.RateLimit.Enabled {
+		return nil
+	}
+
+	return &middleware.RateLimits{
+		Requests:      ratelimit.New(config.RateLimit.RequestsPerMinute, config.RateLimit.RequestsBurst),
+		Writes:        ratelimit.New(config.RateLimit.WritesPerMinute, config.RateLimit.WritesBurst),
+		Subscriptions: ratelimit.New(config.RateLimit.SubscriptionsPerMinute, config.RateLimit.SubscriptionsBurst),
+		ApiKeys:       config.RateLimit.KnownApiKeys(),
+	}
+}
+
+func newVerifier(config *config.Config) (*auth.Verifier, error) {
+	authKey, err := config.Auth.LoadKey()
+	if err != nil {
+		return nil, fmt.Errorf("load auth key: %w", err)
+	}
+	verifier, err := auth.New(
+		config.Auth.Alg,
+		authKey,
+		auth.Issuer(config.Auth.Issuer),
+		auth.Audience(config.Auth.Audience),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("set up auth: %w", err)
+	}
+
+	return verifier, nil
+}
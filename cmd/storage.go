@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/elusiv0/oz_task/internal/config"
+	"github.com/elusiv0/oz_task/internal/migrations"
+	"github.com/elusiv0/oz_task/internal/repo"
+	imCommentRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/comment"
+	imPostRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/post"
+	imUserRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/user"
+	pgCommentRepo "github.com/elusiv0/oz_task/internal/repo/postgres/comment"
+	pgPostRepo "github.com/elusiv0/oz_task/internal/repo/postgres/post"
+	pgUserRepo "github.com/elusiv0/oz_task/internal/repo/postgres/user"
+	"github.com/elusiv0/oz_task/internal/service"
+	commentService "github.com/elusiv0/oz_task/internal/service/comment"
+	postService "github.com/elusiv0/oz_task/internal/service/post"
+	searchService "github.com/elusiv0/oz_task/internal/service/search"
+	userService "github.com/elusiv0/oz_task/internal/service/user"
+	"github.com/elusiv0/oz_task/pkg/postgres"
+)
+
+// storage holds the repos of the configured db, pg is nil for the in-memory db.
+type storage struct {
+	pg          *postgres.Postgres
+	postRepo    repo.PostRepo
+	commentRepo repo.CommentRepo
+	userRepo    repo.UserRepo
+}
+
+type services struct {
+	comment service.CommentService
+	post    service.PostService
+	user    service.UserService
+	search  service.SearchService
+}
+
+// newStorage builds the repos, for postgres it refuses to go on while the schema is behind the migrations.
+func newStorage(ctx context.Context, config *config.Config, logger *slog.Logger) (*storage, error) {
+	if config.App.Db != "postgres" {
+		commentRepo := imCommentRepo.New(logger)
+		return &storage{
+			postRepo:    imPostRepo.New(commentRepo, logger),
+			commentRepo: commentRepo,
+			userRepo:    imUserRepo.New(logger),
+		}, nil
+	}
+
+	pg, err := newPostgres(config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("set up pg connection: %w", err)
+	}
+
+	//checking schema
+	migrator, err := migrations.New(pg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+	if err := migrator.Check(ctx); err != nil {
+		return nil, fmt.Errorf("check schema: %w", err)
+	}
+
+	return &storage{
+		pg:          pg,
+		postRepo:    pgPostRepo.New(pg, logger),
+		commentRepo: pgCommentRepo.New(pg, logger),
+		userRepo:    pgUserRepo.New(pg, logger),
+	}, nil
+}
+
+// Close releases the db connections.
+func (s *storage) Close() {
+	if s.pg != nil {
+		s.pg.PgxPool.Close()
+	}
+}
+
+func newServices(config *config.Config, storage *storage, logger *slog.Logger) services {
+	return services{
+		comment: commentService.New(storage.commentRepo, config.App.MaxCommentDepth, logger),
+		post:    postService.New(storage.postRepo, logger),
+		user:    userService.New(storage.userRepo, logger),
+		search:  searchService.New(storage.postRepo, storage.commentRepo, logger),
+	}
+}
+
+func newPostgres(config *config.Config, logger *slog.Logger) (*postgres.Postgres, error) {
+	return postgres.New(
+		postgres.NewConnectionConfig(
+			config.Postgres.Host,
+			config.Postgres.Port,
+			config.Postgres.User,
+			config.Postgres.Password,
+			config.Postgres.Name,
+		),
+		logger,
+		postgres.ConnAttempts(config.Postgres.ConnectionAttempts),
+		postgres.ConnTimeout(config.Postgres.ConnectionTimeout),
+		postgres.MaxPoolSz(config.Postgres.MaxPoolSz),
+	)
+}
+
+// requirePostgres rejects maintenance commands for the in-memory db, its data lives only inside the server process.
+func requirePostgres(config *config.Config, command string) error {
+	if config.App.Db != "postgres" {
+		return fmt.Errorf("%s requires db=postgres", command)
+	}
+
+	return nil
+}
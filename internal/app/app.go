@@ -1,33 +1,100 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/elusiv0/oz_task/pkg/drain"
 	"github.com/elusiv0/oz_task/pkg/httpserver"
 )
 
 type App struct {
-	server *httpserver.HttpServer
-	logger *slog.Logger
+	server          *httpserver.HttpServer
+	logger          *slog.Logger
+	drain           *drain.Drain
+	shutdownTimeout time.Duration
+	onShutdown      []func()
 }
 
+const defaultShutdownTimeout = 3 * time.Second
+
 func New(
 	server *httpserver.HttpServer,
 	logger *slog.Logger,
+	opts ...Option,
 ) *App {
 	app := &App{
-		server: server,
-		logger: logger,
+		server:          server,
+		logger:          logger,
+		drain:           drain.New(),
+		shutdownTimeout: defaultShutdownTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(app)
 	}
 
 	return app
 }
 
+// Run serves until SIGINT or SIGTERM and then shuts the app down gracefully:
+// long-lived connections are drained, in-flight requests are finished within
+// the shutdown timeout of the server and the shutdown hooks are called.
 func (a *App) Run() error {
 	a.logger.Info("starting http server...")
-	if err := a.server.Start(); err != nil {
-		a.logger.Error("error with up httpserver: " + err.Error())
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- a.server.Start()
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			a.logger.Error("error with up httpserver: " + err.Error())
+		}
+		a.shutdownHooks()
 		return err
+	case sig := <-quit:
+		a.logger.Info("shutting down...", slog.String("signal", sig.String()))
+	}
+
+	a.logger.Debug("draining long-lived connections...")
+	a.drain.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+	drained := make(chan error, 1)
+	go func() {
+		drained <- a.drain.Wait(ctx)
+	}()
+
+	a.logger.Debug("stopping http server...")
+	err := a.server.Shutdown()
+	if err != nil {
+		a.logger.Error("error with shutdown httpserver: " + err.Error())
+	}
+	if drainErr := <-drained; drainErr != nil {
+		a.logger.Error("error with drain long-lived connections: " + drainErr.Error())
+		err = errors.Join(err, drainErr)
+	}
+	err = errors.Join(err, <-serverErr)
+
+	a.shutdownHooks()
+	a.logger.Info("app was stopped")
+
+	return err
+}
+
+func (a *App) shutdownHooks() {
+	for _, hook := range a.onShutdown {
+		hook()
 	}
-	return nil
 }
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"time"
+
+	"github.com/elusiv0/oz_task/pkg/drain"
+)
+
+type Option func(a *App)
+
+// Drain sets the drain of long-lived connections, it is started first on shutdown,
+// so subscriptions are closed while the server finishes in-flight requests.
+func Drain(d *drain.Drain) Option {
+	return func(a *App) {
+		a.drain = d
+	}
+}
+
+// ShutdownTimeout bounds the wait for the drained connections, it should match the timeout of the server.
+func ShutdownTimeout(t time.Duration) Option {
+	return func(a *App) {
+		a.shutdownTimeout = t
+	}
+}
+
+// OnShutdown adds a hook called after the server stopped, e.g. closing the db pool.
+func OnShutdown(hook func()) Option {
+	return func(a *App) {
+		a.onShutdown = append(a.onShutdown, hook)
+	}
+}
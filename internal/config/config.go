@@ -2,61 +2,147 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"time"
-
-	"github.com/kelseyhightower/envconfig"
 )
 
+// Every field has a key of the config file and the flag (section.key), an env var and an optional default.
+// Secret fields are redacted when the config is printed.
 type (
 	Config struct {
-		App      App
-		Http     Http
-		Postgres Postgres
+		App       App       `key:"app"`
+		Http      Http      `key:"http"`
+		Postgres  Postgres  `key:"postgres"`
+		Auth      Auth      `key:"auth"`
+		Tracing   Tracing   `key:"tracing"`
+		Log       Log       `key:"log"`
+		RateLimit RateLimit `key:"rate_limit"`
+		Metrics   Metrics   `key:"metrics"`
+		Graphql   Graphql   `key:"graphql"`
 	}
 
 	App struct {
-		Env string `envconfig:"env" required:"true"`
-		Db  string `envconfig:"db" required:"true"`
+		Env             string `key:"env" env:"ENV"`
+		Db              string `key:"db" env:"DB"`
+		MaxCommentDepth int    `key:"max_comment_depth" env:"MAX_COMMENT_DEPTH" default:"32"`
 	}
 
 	Http struct {
-		Host            string        `envconfig:"HTTP_HOST" default:"localhost"`
-		Port            string        `envconfig:"HTTP_PORT" default:"8080"`
-		ReadTimeout     time.Duration `envconfig:"HTTP_READTIMEOUT" default:"5s"`
-		WriteTimeout    time.Duration `envconfig:"HTTP_WRITETIMEOUT" default:"5s"`
-		ShutdownTimeout time.Duration `envconfig:"HTTP_SHUTDOWNTIMEOUT" default:"3s"`
+		Host            string        `key:"host" env:"HTTP_HOST" default:"localhost"`
+		Port            string        `key:"port" env:"HTTP_PORT" default:"8080"`
+		ReadTimeout     time.Duration `key:"read_timeout" env:"HTTP_READTIMEOUT" default:"5s"`
+		WriteTimeout    time.Duration `key:"write_timeout" env:"HTTP_WRITETIMEOUT" default:"5s"`
+		ShutdownTimeout time.Duration `key:"shutdown_timeout" env:"HTTP_SHUTDOWNTIMEOUT" default:"3s"`
+		// TrustedProxies is a comma separated list of addresses and CIDRs allowed to set X-Forwarded-For,
+		// empty trusts no proxy and the client address is the remote address of the connection.
+		TrustedProxies string `key:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES"`
+	}
+
+	Auth struct {
+		Alg      string `key:"alg" env:"AUTH_ALG" default:"HS256"`
+		Secret   string `key:"secret" env:"AUTH_SECRET" secret:"true"`
+		KeyFile  string `key:"key_file" env:"AUTH_KEY_FILE"`
+		Issuer   string `key:"issuer" env:"AUTH_ISSUER"`
+		Audience string `key:"audience" env:"AUTH_AUDIENCE"`
+	}
+
+	// Tracing is exported through OTLP/HTTP, the traceparent header is propagated even when the export is disabled.
+	Tracing struct {
+		Enabled     bool    `key:"enabled" env:"TRACING_ENABLED" default:"false"`
+		Endpoint    string  `key:"endpoint" env:"TRACING_ENDPOINT" default:"localhost:4318"`
+		Insecure    bool    `key:"insecure" env:"TRACING_INSECURE" default:"true"`
+		SampleRatio float64 `key:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1"`
+	}
+
+	// Log overrides the defaults of App.Env, empty level and format keep them.
+	Log struct {
+		Level              string        `key:"level" env:"LOG_LEVEL"`
+		Format             string        `key:"format" env:"LOG_FORMAT"`
+		Output             string        `key:"output" env:"LOG_OUTPUT" default:"stdout"`
+		FileMaxSizeMB      int           `key:"file_max_size_mb" env:"LOG_FILE_MAX_SIZE_MB" default:"100"`
+		FileMaxBackups     int           `key:"file_max_backups" env:"LOG_FILE_MAX_BACKUPS" default:"5"`
+		FileMaxAgeDays     int           `key:"file_max_age_days" env:"LOG_FILE_MAX_AGE_DAYS" default:"7"`
+		SamplingFirst      int           `key:"sampling_first" env:"LOG_SAMPLING_FIRST" default:"0"`
+		SamplingThereafter int           `key:"sampling_thereafter" env:"LOG_SAMPLING_THEREAFTER" default:"100"`
+		SamplingTick       time.Duration `key:"sampling_tick" env:"LOG_SAMPLING_TICK" default:"1s"`
+	}
+
+	// RateLimit sets the token buckets of a client, a bucket is refilled by the per minute rate up to its burst.
+	// ApiKeys is a comma separated list of the keys identifying anonymous clients by the X-Api-Key header.
+	RateLimit struct {
+		Enabled                bool   `key:"enabled" env:"RATE_LIMIT_ENABLED" default:"true"`
+		RequestsPerMinute      int    `key:"requests_per_minute" env:"RATE_LIMIT_REQUESTS_PER_MINUTE" default:"600"`
+		RequestsBurst          int    `key:"requests_burst" env:"RATE_LIMIT_REQUESTS_BURST" default:"60"`
+		WritesPerMinute        int    `key:"writes_per_minute" env:"RATE_LIMIT_WRITES_PER_MINUTE" default:"30"`
+		WritesBurst            int    `key:"writes_burst" env:"RATE_LIMIT_WRITES_BURST" default:"5"`
+		SubscriptionsPerMinute int    `key:"subscriptions_per_minute" env:"RATE_LIMIT_SUBSCRIPTIONS_PER_MINUTE" default:"60"`
+		SubscriptionsBurst     int    `key:"subscriptions_burst" env:"RATE_LIMIT_SUBSCRIPTIONS_BURST" default:"10"`
+		ApiKeys                string `key:"api_keys" env:"RATE_LIMIT_API_KEYS" secret:"true"`
+	}
+
+	// Metrics.Operations is a comma separated list of the operation names labeled in the metrics,
+	// other names are labeled as other.
+	Metrics struct {
+		Operations string `key:"operations" env:"METRICS_OPERATIONS"`
+	}
+
+	// Graphql limits an operation before it is executed, the calculated values are reported in the cost extension.
+	Graphql struct {
+		MaxComplexity int `key:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" default:"1500"`
+		MaxDepth      int `key:"max_depth" env:"GRAPHQL_MAX_DEPTH" default:"15"`
 	}
 
+	// Postgres is required only with db=postgres.
 	Postgres struct {
-		MaxPoolSz          int           `envconfig:"PG_MAX_POOL_SIZE" default:"1"`
-		ConnectionTimeout  time.Duration `envconfig:"PG_CONNECTION_TIMEOUT" default:"3s"`
-		ConnectionAttempts int           `envconfig:"PG_CONNECTION_ATTEMPTS" default:"10"`
-		Host               string        `envconfig:"PG_HOST" required:"true"`
-		Port               string        `envconfig:"PG_PORT" required:"true"`
-		User               string        `envconfig:"PG_USER" required:"true"`
-		Password           string        `envconfig:"PG_PASSWORD" required:"true"`
-		Name               string        `envconfig:"PG_NAME" required:"true"`
+		MaxPoolSz          int           `key:"max_pool_size" env:"PG_MAX_POOL_SIZE" default:"1"`
+		ConnectionTimeout  time.Duration `key:"connection_timeout" env:"PG_CONNECTION_TIMEOUT" default:"3s"`
+		ConnectionAttempts int           `key:"connection_attempts" env:"PG_CONNECTION_ATTEMPTS" default:"10"`
+		Host               string        `key:"host" env:"PG_HOST"`
+		Port               string        `key:"port" env:"PG_PORT"`
+		User               string        `key:"user" env:"PG_USER"`
+		Password           string        `key:"password" env:"PG_PASSWORD" secret:"true"`
+		Name               string        `key:"name" env:"PG_NAME"`
 	}
 )
 
-func NewConfig() (*Config, error) {
-	app := App{}
-	if err := envconfig.Process("", &app); err != nil {
-		return nil, fmt.Errorf("Config - NewConfig: %w", err)
-	}
-	config := Config{}
-	pg := Postgres{}
-	if app.Db == "postgres" {
-		if err := envconfig.Process("", &pg); err != nil {
-			return nil, fmt.Errorf("Config - NewConfig: %w", err)
+// Proxies returns the trusted proxies, nil when none is set.
+func (h Http) Proxies() []string {
+	return splitList(h.TrustedProxies)
+}
+
+// KnownOperations returns the operation names labeled in the metrics.
+func (m Metrics) KnownOperations() []string {
+	return splitList(m.Operations)
+}
+
+// KnownApiKeys returns the api keys of the clients, nil when none is set.
+func (r RateLimit) KnownApiKeys() []string {
+	return splitList(r.ApiKeys)
+}
+
+// splitList splits a comma separated value, nil is returned for an empty one.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
-	httpcfg := Http{}
-	if err := envconfig.Process("", &httpcfg); err != nil {
-		return nil, fmt.Errorf("Config - NewConfig: %w", err)
+
+	return items
+}
+
+// LoadKey returns the key for token verification, the key file takes
+// precedence over the secret from env. For RS256 the key is a PEM public key.
+func (a Auth) LoadKey() ([]byte, error) {
+	if a.KeyFile != "" {
+		key, err := os.ReadFile(a.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Config - Auth - LoadKey: %w", err)
+		}
+		return key, nil
 	}
-	config.App = app
-	config.Postgres = pg
-	config.Http = httpcfg
-	return &config, nil
+
+	return []byte(a.Secret), nil
 }
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// fileEnv names the config file when the -config flag is not passed.
+const fileEnv = "CONFIG_FILE"
+
+type field struct {
+	key    string
+	env    string
+	def    string
+	secret bool
+	value  reflect.Value
+}
+
+// NewConfig builds the config from layers, each one overrides the previous: defaults, the YAML or TOML
+// config file, env and flags. args are the global flags followed by the command, the command and its
+// args are returned. The config is not validated, see Validate.
+func NewConfig(args []string) (*Config, []string, error) {
+	config := &Config{}
+	fields := config.fields()
+
+	flags := flag.NewFlagSet("oz_task", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv(fileEnv), "path of the YAML or TOML config file, $"+fileEnv+" by default")
+	overrides := make(map[string]string)
+	for _, field := range fields {
+		key := field.key
+		flags.Func(key, "overrides $"+field.env, func(value string) error {
+			overrides[key] = value
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("Config - NewConfig - parse flags: %w", err)
+	}
+
+	fileValues := make(map[string]string)
+	if *file != "" {
+		var err error
+		fileValues, err = readFile(*file, fields)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Config - NewConfig - read %s: %w", *file, err)
+		}
+	}
+
+	for _, field := range fields {
+		layers := []struct {
+			name  string
+			value string
+			ok    bool
+		}{
+			{name: "default", value: field.def, ok: field.def != ""},
+			{name: "file", value: fileValues[field.key], ok: hasKey(fileValues, field.key)},
+			{name: "env $" + field.env, value: os.Getenv(field.env), ok: hasEnv(field.env)},
+			{name: "flag -" + field.key, value: overrides[field.key], ok: hasKey(overrides, field.key)},
+		}
+		for _, layer := range layers {
+			if !layer.ok {
+				continue
+			}
+			if err := field.set(layer.value); err != nil {
+				return nil, nil, fmt.Errorf("Config - NewConfig - %s - %s: %w", layer.name, field.key, err)
+			}
+		}
+	}
+
+	return config, flags.Args(), nil
+}
+
+// fields lists the fields of every section of the config.
+func (c *Config) fields() []field {
+	fields := []field{}
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionKey := sections.Type().Field(i).Tag.Get("key")
+		for j := 0; j < section.NumField(); j++ {
+			tag := section.Type().Field(j).Tag
+			fields = append(fields, field{
+				key:    sectionKey + "." + tag.Get("key"),
+				env:    tag.Get("env"),
+				def:    tag.Get("default"),
+				secret: tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+
+	return fields
+}
+
+func (f field) set(raw string) error {
+	switch f.value.Interface().(type) {
+	case time.Duration:
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(duration))
+	case string:
+		f.value.SetString(raw)
+	case int:
+		num, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(num))
+	case bool:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(flag)
+	case float64:
+		num, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		f.value.SetFloat(num)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+
+	return nil
+}
+
+// readFile returns the values of the file by section.key, unknown keys are rejected so typos don't pass silently.
+func readFile(path string, fields []field) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sections := make(map[string]map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &sections)
+	case ".toml":
+		err = toml.Unmarshal(content, &sections)
+	default:
+		err = fmt.Errorf("unsupported extension %q, expected .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.key] = true
+	}
+	values := make(map[string]string)
+	unknown := []string{}
+	for section, keys := range sections {
+		for key, value := range keys {
+			fullKey := section + "." + key
+			if !known[fullKey] {
+				unknown = append(unknown, fullKey)
+				continue
+			}
+			values[fullKey] = fmt.Sprint(value)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.New("unknown keys " + strings.Join(unknown, ", "))
+	}
+
+	return values, nil
+}
+
+func hasKey(values map[string]string, key string) bool {
+	_, ok := values[key]
+	return ok
+}
+
+// hasEnv treats an empty var as unset since docker-compose passes an undefined ${VAR} as empty.
+func hasEnv(env string) bool {
+	return os.Getenv(env) != ""
+}
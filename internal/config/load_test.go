@@ -0,0 +1,234 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets the env of every field, so the env of the machine doesn't leak into the layers.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv(fileEnv, "")
+	for _, field := range (&Config{}).fields() {
+		t.Setenv(field.env, "")
+	}
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestNewConfigLayers(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "app:\n  env: dev\nhttp:\n  port: \"9000\"\n  read_timeout: 10s\n",
+		"config.toml": "[app]\nenv = \"dev\"\n[http]\nport = \"9000\"\nread_timeout = \"10s\"\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("CONFIG_FILE", writeFile(t, name, content))
+			t.Setenv("ENV", "prod")
+			t.Setenv("HTTP_READTIMEOUT", "20s")
+
+			config, rest, err := NewConfig([]string{"-app.env=local", "serve", "-x"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rest, []string{"serve", "-x"}) {
+				t.Errorf("got args %v, want the command and its args", rest)
+			}
+			tests := []struct {
+				layer string
+				got   any
+				want  any
+			}{
+				{"default", config.Http.WriteTimeout, 5 * time.Second},
+				{"file over default", config.Http.Port, "9000"},
+				{"env over file", config.Http.ReadTimeout, 20 * time.Second},
+				{"flag over env", config.App.Env, "local"},
+			}
+			for _, tt := range tests {
+				if tt.got != tt.want {
+					t.Errorf("%s: got %v, want %v", tt.layer, tt.got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewConfigFileFlag(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "env.yaml", "http:\n  port: \"9000\"\n"))
+	path := writeFile(t, "flag.yaml", "http:\n  port: \"9100\"\n")
+
+	config, _, err := NewConfig([]string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Http.Port != "9100" {
+		t.Errorf("got port %q, want the one of the -config file", config.Http.Port)
+	}
+}
+
+// TestNewConfigEmptyEnv keeps the lower layer for an empty var, docker-compose passes an undefined ${VAR} as empty.
+func TestNewConfigEmptyEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("HTTP_PORT", "")
+
+	config, _, err := NewConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Http.Port != "8080" {
+		t.Errorf("got port %q, want the default", config.Http.Port)
+	}
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		args    []string
+		want    string
+	}{
+		{name: "unknown key", file: "config.yaml", content: "http:\n  prot: \"9000\"\n  por: \"1\"\n", want: "unknown keys http.por, http.prot"},
+		{name: "unknown extension", file: "config.json", content: "{}", want: "unsupported extension"},
+		{name: "broken file", file: "config.yaml", content: "http: [", want: "read"},
+		{name: "file value", file: "config.yaml", content: "http:\n  read_timeout: soon\n", want: "file - http.read_timeout"},
+		{name: "env value", env: map[string]string{"PG_MAX_POOL_SIZE": "many"}, want: "env $PG_MAX_POOL_SIZE - postgres.max_pool_size"},
+		{name: "flag value", args: []string{"-rate_limit.enabled=maybe"}, want: "flag -rate_limit.enabled - rate_limit.enabled"},
+		{name: "unknown flag", args: []string{"-http.prot=9000"}, want: "parse flags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			if tt.file != "" {
+				t.Setenv("CONFIG_FILE", writeFile(t, tt.file, tt.content))
+			}
+			for env, value := range tt.env {
+				t.Setenv(env, value)
+			}
+			_, _, err := NewConfig(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("AUTH_SECRET", "jwt-secret")
+	t.Setenv("PG_PASSWORD", "pg-password")
+	t.Setenv("RATE_LIMIT_API_KEYS", "key-1,key-2")
+	t.Setenv("HTTP_READTIMEOUT", "1m30s")
+	config, _, err := NewConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	if err := config.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"jwt-secret", "pg-password", "key-1"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("printed config has the secret %q:\n%s", secret, out.String())
+		}
+	}
+	for _, line := range []string{`  secret: "<redacted>"`, `  password: "<redacted>"`, `  api_keys: "<redacted>"`, `  key_file: ""`} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("printed config has no line %q:\n%s", line, out.String())
+		}
+	}
+
+	// the printed config is read back as it was, except for the secrets
+	clearEnv(t)
+	printed, _, err := NewConfig([]string{"-config", writeFile(t, "printed.yaml", out.String())})
+	if err != nil {
+		t.Fatalf("read the printed config: %v", err)
+	}
+	printed.Auth.Secret, printed.Postgres.Password, printed.RateLimit.ApiKeys = config.Auth.Secret, config.Postgres.Password, config.RateLimit.ApiKeys
+	if !reflect.DeepEqual(printed, config) {
+		t.Errorf("got %+v, want %+v", printed, config)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func(t *testing.T) *Config {
+		clearEnv(t)
+		t.Setenv("ENV", "local")
+		t.Setenv("DB", "inmemory")
+		t.Setenv("AUTH_SECRET", "secret")
+		config, _, err := NewConfig(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return config
+	}
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string
+	}{
+		{name: "defaults", change: func(c *Config) {}},
+		{
+			name:   "postgres",
+			change: func(c *Config) { c.App.Db = DbPostgres; c.Postgres.Host = "db" },
+			want:   []string{"postgres.port is required", "postgres.password is required", "postgres.name is required"},
+		},
+		{
+			name:   "every rule at once",
+			change: func(c *Config) { c.App.Env = "stage"; c.Auth.Secret = ""; c.Tracing.SampleRatio = 2 },
+			want:   []string{"app.env must be one of", "auth.secret or auth.key_file is required", "tracing.sample_ratio must be between 0 and 1"},
+		},
+		{
+			name:   "rate limit",
+			change: func(c *Config) { c.RateLimit.WritesBurst = 0 },
+			want:   []string{"rate_limit.writes_per_minute and rate_limit.writes_burst must be positive"},
+		},
+		{
+			name:   "disabled rate limit",
+			change: func(c *Config) { c.RateLimit.Enabled = false; c.RateLimit.WritesBurst = 0 },
+		},
+		{
+			name:   "trusted proxies",
+			change: func(c *Config) { c.Http.TrustedProxies = "10.0.0.0/8, 192.168.1.1,proxy" },
+			want:   []string{`http.trusted_proxies has invalid address "proxy"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid(t)
+			tt.change(config)
+			err := config.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("got %v, want a valid config", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("got a valid config, want errors %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "<redacted>"
+
+// Print writes the config as YAML that NewConfig can read back, values of secret fields are redacted.
+func (c *Config) Print(w io.Writer) error {
+	section := ""
+	for _, field := range c.fields() {
+		sectionKey, key, _ := strings.Cut(field.key, ".")
+		if sectionKey != section {
+			section = sectionKey
+			if _, err := fmt.Fprintf(w, "%s:\n", section); err != nil {
+				return fmt.Errorf("Config - Print: %w", err)
+			}
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s\n", key, field.format()); err != nil {
+			return fmt.Errorf("Config - Print: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (f field) format() string {
+	switch value := f.value.Interface().(type) {
+	case string:
+		if f.secret && value != "" {
+			value = redacted
+		}
+		return strconv.Quote(value)
+	case time.Duration:
+		return strconv.Quote(value.String())
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+
+	"github.com/elusiv0/oz_task/pkg/auth"
+)
+
+const DbPostgres = "postgres"
+
+var (
+	envs       = []string{"local", "dev", "prod"}
+	dbs        = []string{DbPostgres, "inmemory", "in-memory"}
+	logFormats = []string{"", "tint", "json", "logfmt"}
+)
+
+// Validate checks the values together, every broken rule is reported at once.
+func (c *Config) Validate() error {
+	errs := []error{}
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(slices.Contains(envs, c.App.Env), "app.env must be one of %v, got %q", envs, c.App.Env)
+	check(slices.Contains(dbs, c.App.Db), "app.db must be one of %v, got %q", dbs, c.App.Db)
+	check(c.App.MaxCommentDepth >= 1, "app.max_comment_depth must be positive")
+
+	check(c.Http.Port != "", "http.port is required")
+	check(c.Http.ReadTimeout > 0, "http.read_timeout must be positive")
+	check(c.Http.WriteTimeout > 0, "http.write_timeout must be positive")
+	check(c.Http.ShutdownTimeout > 0, "http.shutdown_timeout must be positive")
+	for _, proxy := range c.Http.Proxies() {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "http.trusted_proxies has invalid address %q", proxy)
+	}
+
+	switch c.Auth.Alg {
+	case auth.HS256:
+		check(c.Auth.Secret != "" || c.Auth.KeyFile != "", "auth.secret or auth.key_file is required for %s", auth.HS256)
+	case auth.RS256:
+		check(c.Auth.KeyFile != "", "auth.key_file is required for %s", auth.RS256)
+	default:
+		check(false, "auth.alg must be one of %s and %s, got %q", auth.HS256, auth.RS256, c.Auth.Alg)
+	}
+
+	if c.App.Db == DbPostgres {
+		check(c.Postgres.Host != "", "postgres.host is required for db=postgres")
+		check(c.Postgres.Port != "", "postgres.port is required for db=postgres")
+		check(c.Postgres.User != "", "postgres.user is required for db=postgres")
+		check(c.Postgres.Password != "", "postgres.password is required for db=postgres")
+		check(c.Postgres.Name != "", "postgres.name is required for db=postgres")
+		check(c.Postgres.MaxPoolSz >= 1, "postgres.max_pool_size must be positive")
+		check(c.Postgres.ConnectionAttempts >= 1, "postgres.connection_attempts must be positive")
+	}
+
+	if c.Tracing.Enabled {
+		check(c.Tracing.Endpoint != "", "tracing.endpoint is required when tracing is enabled")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.RequestsPerMinute > 0 && c.RateLimit.RequestsBurst > 0, "rate_limit.requests_per_minute and rate_limit.requests_burst must be positive")
+		check(c.RateLimit.WritesPerMinute > 0 && c.RateLimit.WritesBurst > 0, "rate_limit.writes_per_minute and rate_limit.writes_burst must be positive")
+		check(c.RateLimit.SubscriptionsPerMinute > 0 && c.RateLimit.SubscriptionsBurst > 0, "rate_limit.subscriptions_per_minute and rate_limit.subscriptions_burst must be positive")
+	}
+
+	check(c.Graphql.MaxComplexity > 0, "graphql.max_complexity must be positive")
+	check(c.Graphql.MaxDepth > 0, "graphql.max_depth must be positive")
+
+	if c.Log.Level != "" {
+		var level slog.Level
+		check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level %q is unknown", c.Log.Level)
+	}
+	check(slices.Contains(logFormats, c.Log.Format), "log.format must be one of tint, json and logfmt, got %q", c.Log.Format)
+	check(c.Log.Output != "", "log.output is required")
+	check(c.Log.SamplingFirst >= 0 && c.Log.SamplingThereafter >= 0, "log sampling counts must not be negative")
+	if c.Log.SamplingFirst > 0 {
+		check(c.Log.SamplingTick > 0, "log.sampling_tick must be positive when sampling is enabled")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Config - Validate: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
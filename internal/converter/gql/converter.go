@@ -9,18 +9,14 @@ import (
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
-func ToPostConnection(postDto []*dto.Post, first int, after *int) *graph.PostConnection {
+func ToPostConnection(postDto []*dto.Post, page dto.Page) *graph.PostConnection {
 	var edges []*graph.PostEdge
-	hasNext := false
-	if len(postDto) > first {
-		hasNext = true
-		postDto = postDto[:len(postDto)-1]
-	}
-	pageInfo := getPageInfo(postDto[0].ID, postDto[len(postDto)-1].ID, &hasNext)
+	postDto, hasPrev, hasNext := window(postDto, page)
 	for _, val := range postDto {
 		curPostEdge := toPostEdge(val)
 		edges = append(edges, curPostEdge)
 	}
+	pageInfo := getPageInfo(edges[0].Cursor, edges[len(edges)-1].Cursor, &hasPrev, &hasNext)
 
 	return &graph.PostConnection{
 		Edges:    edges,
@@ -31,22 +27,18 @@ func ToPostConnection(postDto []*dto.Post, first int, after *int) *graph.PostCon
 func toPostEdge(postDto *dto.Post) *graph.PostEdge {
 	return &graph.PostEdge{
 		Node:   postDto,
-		Cursor: postDto.ID,
+		Cursor: EncodeCursor(cursorOf(postDto.Cursor, postDto.ID)),
 	}
 }
 
-func ToCommentConnection(commentDto []*dto.Comment, first int, after *int) *graph.CommentConnection {
+func ToCommentConnection(commentDto []*dto.Comment, page dto.Page) *graph.CommentConnection {
 	var edges []*graph.CommentEdge
-	hasNext := false
-	if len(commentDto) > first {
-		hasNext = true
-		commentDto = commentDto[:len(commentDto)-1]
-	}
-	pageInfo := getPageInfo(commentDto[0].ID, commentDto[len(commentDto)-1].ID, &hasNext)
+	commentDto, hasPrev, hasNext := window(commentDto, page)
 	for _, val := range commentDto {
 		curCommentEdge := toCommentEdge(val)
 		edges = append(edges, curCommentEdge)
 	}
+	pageInfo := getPageInfo(edges[0].Cursor, edges[len(edges)-1].Cursor, &hasPrev, &hasNext)
 
 	return &graph.CommentConnection{
 		Edges:    edges,
@@ -57,15 +49,127 @@ func ToCommentConnection(commentDto []*dto.Comment, first int, after *int) *grap
 func toCommentEdge(commentDto *dto.Comment) *graph.CommentEdge {
 	return &graph.CommentEdge{
 		Node:   commentDto,
-		Cursor: commentDto.ID,
+		Cursor: EncodeCursor(cursorOf(commentDto.Cursor, commentDto.ID)),
+	}
+}
+
+func ToUserConnection(userDto []*dto.User, first int, after *int) *graph.UserConnection {
+	var edges []*graph.UserEdge
+	hasNext := false
+	if len(userDto) > first {
+		hasNext = true
+		userDto = userDto[:len(userDto)-1]
+	}
+	for _, val := range userDto {
+		curUserEdge := toUserEdge(val)
+		edges = append(edges, curUserEdge)
+	}
+	hasPrev := after != nil
+	start, end := "", ""
+	if len(edges) > 0 {
+		start, end = edges[0].Cursor, edges[len(edges)-1].Cursor
+	}
+	pageInfo := getPageInfo(start, end, &hasPrev, &hasNext)
+
+	return &graph.UserConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}
+}
+
+func toUserEdge(userDto *dto.User) *graph.UserEdge {
+	return &graph.UserEdge{
+		Node:   userDto,
+		Cursor: EncodeCursor(cursorOf(nil, userDto.ID)),
+	}
+}
+
+// ToSearchConnection builds cursors from positions of the results in the ranked list.
+// The after is the position of the last result of the previous page.
+func ToSearchConnection(searchDto []*dto.SearchResult, first int, after *int) *graph.SearchConnection {
+	var edges []*graph.SearchEdge
+	hasNext := false
+	if len(searchDto) > first {
+		hasNext = true
+		searchDto = searchDto[:len(searchDto)-1]
+	}
+	offset := 0
+	if after != nil {
+		offset = *after
+	}
+	for idx, val := range searchDto {
+		curSearchEdge := toSearchEdge(val, offset+idx+1)
+		edges = append(edges, curSearchEdge)
+	}
+	hasPrev := offset > 0
+	start, end := "", ""
+	if len(edges) > 0 {
+		start, end = edges[0].Cursor, edges[len(edges)-1].Cursor
+	}
+	pageInfo := getPageInfo(start, end, &hasPrev, &hasNext)
+
+	return &graph.SearchConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
 	}
 }
 
-func getPageInfo(first int, end int, hasNext *bool) *graph.PageInfo {
+func toSearchEdge(searchDto *dto.SearchResult, position int) *graph.SearchEdge {
+	return &graph.SearchEdge{
+		Node:   searchDto,
+		Cursor: EncodeCursor(dto.Cursor{Order: dto.OrderRelevance, Offset: position}),
+	}
+}
+
+// ToThread drops the extra node fetched by the repo to find out whether the thread was truncated.
+func ToThread(threadDto []*dto.ThreadNode, maxNodes int) *graph.Thread {
+	truncated := false
+	if len(threadDto) > maxNodes {
+		truncated = true
+		threadDto = threadDto[:maxNodes]
+	}
+
+	return &graph.Thread{
+		Nodes:     threadDto,
+		Truncated: truncated,
+	}
+}
+
+// cursorOf returns the cursor set by the repo or the newest-first cursor of the row.
+func cursorOf(cursor *dto.Cursor, id int) dto.Cursor {
+	if cursor != nil {
+		return *cursor
+	}
+
+	return dto.Cursor{Order: dto.OrderNewest, Id: id}
+}
+
+// window drops the extra row fetched by the repo to find out whether there is one more page.
+// The page in the opposite direction is only known to exist when the client came from it with a cursor.
+func window[T any](rows []T, page dto.Page) ([]T, bool, bool) {
+	if page.Backward() {
+		hasPrev := false
+		if len(rows) > page.Last {
+			hasPrev = true
+			rows = rows[1:]
+		}
+		return rows, hasPrev, page.Before != nil
+	}
+	hasNext := false
+	if len(rows) > page.First {
+		hasNext = true
+		rows = rows[:len(rows)-1]
+	}
+
+	return rows, page.After != nil, hasNext
+}
+
+func getPageInfo(first string, end string, hasPrev *bool, hasNext *bool) *graph.PageInfo {
 	return &graph.PageInfo{
-		StartCursor: first,
-		EndCursor:   end,
-		HasNextPage: hasNext,
+		StartCursor:     first,
+		EndCursor:       end,
+		HasNextPage:     hasNext,
+		HasPreviousPage: hasPrev,
 	}
 }
 
@@ -87,6 +191,36 @@ func ToGetPostsRequest(opts ...postsReqOptions) dto.GetPostsRequest {
 	return *postsReq
 }
 
+func ToGetUsersRequest(opts ...usersReqOptions) dto.GetUsersRequest {
+	usersReq := &dto.GetUsersRequest{}
+	for _, opt := range opts {
+		opt(usersReq)
+	}
+
+	return *usersReq
+}
+
+func ToSearchRequest(query string, first int, after *dto.Cursor) dto.SearchRequest {
+	searchReq := dto.SearchRequest{
+		Query: query,
+		First: first,
+	}
+	if after != nil {
+		searchReq.After = &after.Offset
+	}
+
+	return searchReq
+}
+
+func ToThreadRequest(commentId *int, postId *int, maxDepth int, maxNodes int) dto.ThreadRequest {
+	return dto.ThreadRequest{
+		CommentId: commentId,
+		PostId:    postId,
+		MaxDepth:  maxDepth,
+		MaxNodes:  maxNodes,
+	}
+}
+
 func ToGqlError(ctx context.Context, cErr *dto.CustomError) *gqlerror.Error {
 	return &gqlerror.Error{
 		Message: cErr.Error(),
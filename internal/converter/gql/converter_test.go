@@ -0,0 +1,58 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+func TestToPostConnection(t *testing.T) {
+	cursor := &dto.Cursor{Order: dto.OrderNewest, Id: 9}
+	posts := func(ids ...int) []*dto.Post {
+		postsDto := make([]*dto.Post, 0, len(ids))
+		for _, id := range ids {
+			postsDto = append(postsDto, &dto.Post{ID: id})
+		}
+		return postsDto
+	}
+	tests := []struct {
+		name     string
+		posts    []*dto.Post
+		page     dto.Page
+		want     []int
+		wantPrev bool
+		wantNext bool
+	}{
+		{"first page", posts(8, 7, 6), dto.Page{First: 2}, []int{8, 7}, false, true},
+		{"last forward page", posts(8, 7), dto.Page{First: 2, After: cursor}, []int{8, 7}, true, false},
+		{"middle forward page", posts(8, 7, 6), dto.Page{First: 2, After: cursor}, []int{8, 7}, true, true},
+		// backward pages keep the display order, the extra row comes first
+		{"last page", posts(3, 2, 1), dto.Page{Last: 2}, []int{2, 1}, true, false},
+		{"first backward page", posts(3, 2), dto.Page{Last: 2, Before: cursor}, []int{3, 2}, false, true},
+		{"middle backward page", posts(4, 3, 2), dto.Page{Last: 2, Before: cursor}, []int{3, 2}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := ToPostConnection(tt.posts, tt.page)
+			got := []int{}
+			for _, edge := range conn.Edges {
+				got = append(got, edge.Node.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got posts %v, want %v", got, tt.want)
+			}
+			if conn.PageInfo.HasPreviousPage == nil || *conn.PageInfo.HasPreviousPage != tt.wantPrev {
+				t.Errorf("got hasPreviousPage %v, want %v", conn.PageInfo.HasPreviousPage, tt.wantPrev)
+			}
+			if conn.PageInfo.HasNextPage == nil || *conn.PageInfo.HasNextPage != tt.wantNext {
+				t.Errorf("got hasNextPage %v, want %v", conn.PageInfo.HasNextPage, tt.wantNext)
+			}
+			start := EncodeCursor(dto.Cursor{Order: dto.OrderNewest, Id: tt.want[0]})
+			end := EncodeCursor(dto.Cursor{Order: dto.OrderNewest, Id: tt.want[len(tt.want)-1]})
+			if conn.PageInfo.StartCursor != start || conn.PageInfo.EndCursor != end {
+				t.Errorf("got cursors %q %q, want %q %q", conn.PageInfo.StartCursor, conn.PageInfo.EndCursor, start, end)
+			}
+		})
+	}
+}
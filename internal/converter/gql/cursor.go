@@ -0,0 +1,141 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+var (
+	InvalidCursorErr = dto.ErrInfo{
+		ErrorMessage: "cursor is malformed or doesn't match the requested order",
+		StatusCode:   http.StatusBadRequest,
+	}
+	InvalidPageErr = dto.ErrInfo{
+		ErrorMessage: "first and after can't be combined with last and before, page size must be positive",
+		StatusCode:   http.StatusBadRequest,
+	}
+	InvalidPageSizeErr = dto.ErrInfo{
+		ErrorMessage: fmt.Sprintf("page size must be between 1 and %d", MaxPageSize),
+		StatusCode:   http.StatusBadRequest,
+	}
+)
+
+const (
+	// DefaultPageSize is the size of a page when the client passes neither first nor last.
+	DefaultPageSize = 10
+	// MaxPageSize bounds the first argument of the connections paged only forward.
+	MaxPageSize = 100
+	// DefaultThreadDepth and DefaultThreadNodes are the schema defaults of the thread limits.
+	DefaultThreadDepth = 10
+	DefaultThreadNodes = 100
+)
+
+// ValueOr returns the argument or the default when the client passed null explicitly,
+// the schema default doesn't apply then.
+func ValueOr(arg *int, def int) int {
+	if arg == nil {
+		return def
+	}
+
+	return *arg
+}
+
+// ToPageSize checks the first argument of the connections paged only forward,
+// null falls back to the default page size.
+func ToPageSize(first *int) (int, error) {
+	size := ValueOr(first, DefaultPageSize)
+	if size < 1 || size > MaxPageSize {
+		return 0, dto.NewCustomError(InvalidPageSizeErr, map[string]any{"first": size})
+	}
+
+	return size, nil
+}
+
+// EncodeCursor returns the opaque representation of the cursor given to the clients.
+func EncodeCursor(cursor dto.Cursor) string {
+	raw, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ToCursor decodes the cursor got from the client and checks it was issued for the order.
+func ToCursor(after *string, order dto.Order) (*dto.Cursor, error) {
+	if after == nil {
+		return nil, nil
+	}
+	invalidErr := dto.NewCustomError(InvalidCursorErr, map[string]any{"after": *after, "order": order})
+
+	raw, err := base64.RawURLEncoding.DecodeString(*after)
+	if err != nil {
+		return nil, invalidErr
+	}
+	cursor := &dto.Cursor{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cursor); err != nil {
+		return nil, invalidErr
+	}
+	if cursor.Order != order || !validCursor(cursor) {
+		return nil, invalidErr
+	}
+
+	return cursor, nil
+}
+
+func validCursor(cursor *dto.Cursor) bool {
+	if cursor.Order == dto.OrderRelevance {
+		return cursor.Offset > 0 && cursor.Id == 0
+	}
+	if cursor.Id <= 0 || cursor.Offset != 0 {
+		return false
+	}
+	switch cursor.Order {
+	case dto.OrderNewest, dto.OrderOldest:
+		return cursor.Replies == nil && cursor.Activity == nil
+	case dto.OrderMostReplies:
+		return cursor.Replies != nil && *cursor.Replies >= 0 && cursor.Activity == nil
+	case dto.OrderRecentActivity:
+		return cursor.Activity != nil && cursor.Replies == nil
+	}
+
+	return false
+}
+
+// ToPage checks the pagination arguments of the connection and decodes its cursors.
+// The connection is paged forward by default and backward when last or before is passed.
+func ToPage(first *int, after *string, last *int, before *string, order dto.Order) (dto.Page, error) {
+	page := dto.Page{}
+	forward := first != nil || after != nil
+	backward := last != nil || before != nil
+	if (forward && backward) || (first != nil && *first < 1) || (last != nil && *last < 1) {
+		return page, dto.NewCustomError(InvalidPageErr, map[string]any{
+			"first": first, "after": after, "last": last, "before": before,
+		})
+	}
+
+	var err error
+	if page.After, err = ToCursor(after, order); err != nil {
+		return page, err
+	}
+	if page.Before, err = ToCursor(before, order); err != nil {
+		return page, err
+	}
+	if backward {
+		page.Last = DefaultPageSize
+		if last != nil {
+			page.Last = *last
+		}
+		return page, nil
+	}
+	page.First = DefaultPageSize
+	if first != nil {
+		page.First = *first
+	}
+
+	return page, nil
+}
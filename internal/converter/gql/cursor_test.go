@@ -0,0 +1,149 @@
+package converter
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	replies := 3
+	activity := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	tests := []dto.Cursor{
+		{Order: dto.OrderNewest, Id: 7},
+		{Order: dto.OrderOldest, Id: 1},
+		{Order: dto.OrderMostReplies, Id: 7, Replies: &replies},
+		{Order: dto.OrderRecentActivity, Id: 7, Activity: &activity},
+		{Order: dto.OrderRelevance, Offset: 20},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.Order), func(t *testing.T) {
+			encoded := EncodeCursor(tt)
+			got, err := ToCursor(&encoded, tt.Order)
+			if err != nil {
+				t.Fatalf("decode %q: %v", encoded, err)
+			}
+			if !reflect.DeepEqual(*got, tt) {
+				t.Errorf("got %+v, want %+v", *got, tt)
+			}
+		})
+	}
+}
+
+func TestToCursorRejects(t *testing.T) {
+	replies := 3
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+	tests := []struct {
+		name   string
+		cursor string
+		order  dto.Order
+	}{
+		{"raw id", "7", dto.OrderNewest},
+		{"not base64", "!!!", dto.OrderNewest},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"o":"NEWEST","id":17}`)), dto.OrderNewest},
+		{"not json", encode("7"), dto.OrderNewest},
+		{"unknown field", encode(`{"o":"NEWEST","id":7,"x":1}`), dto.OrderNewest},
+		{"other order", EncodeCursor(dto.Cursor{Order: dto.OrderOldest, Id: 7}), dto.OrderNewest},
+		{"no id", encode(`{"o":"NEWEST"}`), dto.OrderNewest},
+		{"negative id", encode(`{"o":"NEWEST","id":-1}`), dto.OrderNewest},
+		{"offset of an id order", encode(`{"o":"NEWEST","id":7,"off":3}`), dto.OrderNewest},
+		{"sort key of another order", EncodeCursor(dto.Cursor{Order: dto.OrderNewest, Id: 7, Replies: &replies}), dto.OrderNewest},
+		{"no replies", encode(`{"o":"MOST_REPLIES","id":7}`), dto.OrderMostReplies},
+		{"negative replies", encode(`{"o":"MOST_REPLIES","id":7,"r":-1}`), dto.OrderMostReplies},
+		{"no activity", encode(`{"o":"RECENT_ACTIVITY","id":7}`), dto.OrderRecentActivity},
+		{"no offset", encode(`{"o":"RELEVANCE"}`), dto.OrderRelevance},
+		{"id of relevance", encode(`{"o":"RELEVANCE","id":7,"off":3}`), dto.OrderRelevance},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ToCursor(&tt.cursor, tt.order)
+			assertStatus(t, err, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestToPage(t *testing.T) {
+	after := EncodeCursor(dto.Cursor{Order: dto.OrderNewest, Id: 5})
+	zero, one, two := 0, 1, 2
+	tests := []struct {
+		name    string
+		first   *int
+		after   *string
+		last    *int
+		before  *string
+		want    dto.Page
+		wantErr bool
+	}{
+		{name: "defaults", want: dto.Page{First: DefaultPageSize}},
+		{name: "first", first: &two, want: dto.Page{First: 2}},
+		{name: "after", after: &after, want: dto.Page{First: DefaultPageSize, After: &dto.Cursor{Order: dto.OrderNewest, Id: 5}}},
+		{name: "last", last: &one, want: dto.Page{Last: 1}},
+		{name: "before", before: &after, want: dto.Page{Last: DefaultPageSize, Before: &dto.Cursor{Order: dto.OrderNewest, Id: 5}}},
+		{name: "both directions", first: &one, last: &one, wantErr: true},
+		{name: "after and last", after: &after, last: &one, wantErr: true},
+		{name: "zero first", first: &zero, wantErr: true},
+		{name: "zero last", last: &zero, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToPage(tt.first, tt.after, tt.last, tt.before, dto.OrderNewest)
+			if tt.wantErr {
+				assertStatus(t, err, http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToPageSize(t *testing.T) {
+	size := func(v int) *int { return &v }
+	tests := []struct {
+		name    string
+		first   *int
+		want    int
+		wantErr bool
+	}{
+		{name: "null", want: DefaultPageSize},
+		{name: "one", first: size(1), want: 1},
+		{name: "max", first: size(MaxPageSize), want: MaxPageSize},
+		{name: "zero", first: size(0), wantErr: true},
+		{name: "negative", first: size(-3), wantErr: true},
+		{name: "over max", first: size(MaxPageSize + 1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToPageSize(tt.first)
+			if tt.wantErr {
+				assertStatus(t, err, http.StatusBadRequest)
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var cErr *dto.CustomError
+	if !errors.As(err, &cErr) {
+		t.Fatalf("got error %v, want a custom error", err)
+	}
+	if cErr.GetStatus() != status {
+		t.Errorf("got status %d, want %d", cErr.GetStatus(), status)
+	}
+}
@@ -2,14 +2,14 @@ package converter
 
 import (
 	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/graph"
 )
 
 type commentsReqOptions func(*dto.GetCommentsRequest)
 
-func WithCommentsPagination(first int, after *int) commentsReqOptions {
+func WithCommentsPagination(page dto.Page) commentsReqOptions {
 	return func(c *dto.GetCommentsRequest) {
-		c.After = after
-		c.First = first
+		c.Page = page
 	}
 }
 
@@ -25,11 +25,53 @@ func WithParentId(parentId *int) commentsReqOptions {
 	}
 }
 
+func WithCommentsAuthorId(authorId *int) commentsReqOptions {
+	return func(c *dto.GetCommentsRequest) {
+		c.AuthorId = authorId
+	}
+}
+
+func WithCommentsOrder(order *graph.Order) commentsReqOptions {
+	return func(c *dto.GetCommentsRequest) {
+		c.Order = ToOrder(order)
+	}
+}
+
 type postsReqOptions func(*dto.GetPostsRequest)
 
-func WithPostsPagination(first int, after *int) postsReqOptions {
+func WithPostsPagination(page dto.Page) postsReqOptions {
+	return func(p *dto.GetPostsRequest) {
+		p.Page = page
+	}
+}
+
+func WithPostsAuthorId(authorId *int) postsReqOptions {
 	return func(p *dto.GetPostsRequest) {
-		p.After = after
-		p.First = first
+		p.AuthorId = authorId
+	}
+}
+
+func WithPostsOrder(order *graph.Order) postsReqOptions {
+	return func(p *dto.GetPostsRequest) {
+		p.Order = ToOrder(order)
+	}
+}
+
+func ToOrder(order *graph.Order) dto.Order {
+	if order == nil {
+		return dto.OrderNewest
+	}
+
+	return dto.Order(*order)
+}
+
+type usersReqOptions func(*dto.GetUsersRequest)
+
+func WithUsersPagination(first int, after *dto.Cursor) usersReqOptions {
+	return func(u *dto.GetUsersRequest) {
+		if after != nil {
+			u.After = &after.Id
+		}
+		u.First = first
 	}
 }
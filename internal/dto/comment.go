@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Comment struct {
 	ID        int       `json:"id"`
@@ -8,17 +11,52 @@ type Comment struct {
 	ArticleID int       `json:"articleId"`
 	ParentID  *int      `json:"parentId"`
 	CreatedAt time.Time `json:"createdAt"`
+	Deleted   bool      `json:"deleted"`
+	AuthorID  *int      `json:"authorId"`
+	// Path holds ids of the ancestors starting from the top-level comment.
+	Path   []int   `json:"path"`
+	Depth  int     `json:"depth"`
+	Cursor *Cursor `json:"-"`
+}
+
+type CommentRevision struct {
+	ID        int       `json:"id"`
+	CommentID int       `json:"commentId"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type NewComment struct {
 	Text      string `json:"text"`
 	ArticleID int    `json:"articleId"`
 	ParentID  *int   `json:"parentId,omitempty"`
+	AuthorID  *int   `json:"authorId,omitempty"`
 }
 
 type GetCommentsRequest struct {
-	PostId   *int `json:"post_id"`
-	ParentId *int `json:"parent_id"`
-	After    *int `json:"after"`
-	First    int  `json:"first"`
+	Page
+	PostId   *int  `json:"post_id"`
+	ParentId *int  `json:"parent_id"`
+	AuthorId *int  `json:"author_id"`
+	Order    Order `json:"order"`
+}
+
+// BatchKey is equal for the requests which are fetched by one query: a batch is paged and ordered
+// by its first request and grouped by post, parent or author, so requests with other
+// arguments must be fetched separately.
+func (r GetCommentsRequest) BatchKey() string {
+	by := "post"
+	if r.AuthorId != nil {
+		by = "author"
+	} else if r.ParentId != nil {
+		by = "parent"
+	}
+	// cursors are compared by value, equal pages of different fields share the batch
+	key, _ := json.Marshal(struct {
+		By    string
+		Order Order
+		Page  Page
+	}{by, r.Order, r.Page})
+
+	return string(key)
 }
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// Cursor is a position in the ordered list of rows. It holds the sort key of the row,
+// so the next page is built from the cursor alone and not from the current state of the row.
+type Cursor struct {
+	Order    Order      `json:"o"`
+	Id       int        `json:"id,omitempty"`
+	Replies  *int       `json:"r,omitempty"`
+	Activity *time.Time `json:"a,omitempty"`
+	Offset   int        `json:"off,omitempty"`
+}
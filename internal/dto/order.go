@@ -0,0 +1,15 @@
+package dto
+
+// Order is the sort order of posts and comments, every order is completed by id
+// so that cursors stay stable between pages.
+type Order string
+
+const (
+	OrderNewest         Order = "NEWEST"
+	OrderOldest         Order = "OLDEST"
+	OrderMostReplies    Order = "MOST_REPLIES"
+	OrderRecentActivity Order = "RECENT_ACTIVITY"
+)
+
+// OrderRelevance orders search results, it is not accepted by orderBy.
+const OrderRelevance Order = "RELEVANCE"
@@ -0,0 +1,30 @@
+package dto
+
+// Page is the window of the connection requested by the client,
+// Last is set only when the connection is paged backward.
+type Page struct {
+	First  int     `json:"first"`
+	After  *Cursor `json:"after"`
+	Last   int     `json:"last"`
+	Before *Cursor `json:"before"`
+}
+
+// Backward reports whether the page is counted from the before cursor.
+func (p Page) Backward() bool {
+	return p.Last > 0
+}
+
+// SameBatch reports whether all the requests can be fetched by one query.
+func SameBatch[T interface{ BatchKey() string }](reqs ...T) bool {
+	if len(reqs) == 0 {
+		return true
+	}
+	key := reqs[0].BatchKey()
+	for _, val := range reqs[1:] {
+		if val.BatchKey() != key {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,22 +1,52 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Post struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Text      string    `json:"text"`
-	Closed    bool      `json:"closed"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        int        `json:"id"`
+	Title     string     `json:"title"`
+	Text      string     `json:"text"`
+	Closed    bool       `json:"closed"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ClosedAt  *time.Time `json:"closedAt"`
+	ClosedBy  *int       `json:"closedBy"`
+	AuthorID  *int       `json:"authorId"`
+	Cursor    *Cursor    `json:"-"`
 }
 
 type NewPost struct {
-	Title  string `json:"title"`
-	Text   string `json:"text"`
-	Closed bool   `json:"closed"`
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+	Closed   bool   `json:"closed"`
+	AuthorID *int   `json:"authorId,omitempty"`
+}
+
+type UpdatePost struct {
+	Title *string `json:"title,omitempty"`
+	Text  *string `json:"text,omitempty"`
 }
 
 type GetPostsRequest struct {
-	First int  `json:"first"`
-	After *int `json:"after"`
+	Page
+	AuthorId *int  `json:"author_id"`
+	Order    Order `json:"order"`
+}
+
+// BatchKey is equal for the requests which are fetched by one query: a batch is paged and ordered
+// by its first request and grouped by author, requests without an author are fetched alone.
+func (r GetPostsRequest) BatchKey() string {
+	by := "all"
+	if r.AuthorId != nil {
+		by = "author"
+	}
+	key, _ := json.Marshal(struct {
+		By    string
+		Order Order
+		Page  Page
+	}{by, r.Order, r.Page})
+
+	return string(key)
 }
@@ -0,0 +1,14 @@
+package dto
+
+type SearchRequest struct {
+	Query string `json:"query"`
+	First int    `json:"first"`
+	After *int   `json:"after"`
+}
+
+// SearchResult holds either a post or a comment matched by the search query.
+type SearchResult struct {
+	Score   float64  `json:"score"`
+	Post    *Post    `json:"post,omitempty"`
+	Comment *Comment `json:"comment,omitempty"`
+}
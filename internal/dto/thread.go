@@ -0,0 +1,14 @@
+package dto
+
+type ThreadRequest struct {
+	CommentId *int `json:"comment_id"`
+	PostId    *int `json:"post_id"`
+	MaxDepth  int  `json:"max_depth"`
+	MaxNodes  int  `json:"max_nodes"`
+}
+
+// ThreadNode is a comment of the flattened thread with its depth relative to the thread root.
+type ThreadNode struct {
+	Depth   int      `json:"depth"`
+	Comment *Comment `json:"comment"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+type User struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type NewUser struct {
+	Username string `json:"username"`
+}
+
+type GetUsersRequest struct {
+	First int  `json:"first"`
+	After *int `json:"after"`
+}
@@ -0,0 +1,51 @@
+package complexity
+
+import (
+	"math"
+
+	gqlconv "github.com/elusiv0/oz_task/internal/converter/gql"
+	"github.com/elusiv0/oz_task/internal/graph"
+)
+
+// maxComplexity caps a field, so nested pages can't overflow into a negative complexity passing the limit.
+const maxComplexity = math.MaxInt32
+
+// Apply sets the complexity of the list fields: a list costs its size times the complexity of an item.
+// Sizes passed as null fall back to the defaults of the resolvers.
+func Apply(c *graph.ComplexityRoot) {
+	counted := func(childComplexity int, first *int, after *string) int {
+		return multiply(gqlconv.ValueOr(first, gqlconv.DefaultPageSize), childComplexity)
+	}
+	ordered := func(childComplexity int, first *int, after *string, last *int, before *string, orderBy *graph.Order) int {
+		size := gqlconv.ValueOr(first, gqlconv.DefaultPageSize)
+		if first == nil && last != nil {
+			size = *last
+		}
+		return multiply(size, childComplexity)
+	}
+
+	c.Post.Comments = ordered
+	c.Query.Posts = ordered
+	c.Comment.Comments = ordered
+	c.User.Posts = ordered
+	c.User.Comments = ordered
+	c.Query.Users = counted
+	c.Query.Search = func(childComplexity int, query string, first *int, after *string) int {
+		return counted(childComplexity, first, after)
+	}
+	c.Query.Thread = func(childComplexity int, commentID *int, postID *int, maxDepth *int, maxNodes *int) int {
+		return multiply(gqlconv.ValueOr(maxNodes, gqlconv.DefaultThreadNodes), childComplexity)
+	}
+}
+
+// multiply saturates at maxComplexity. A size below one is priced as one: the page sizes are validated
+// after the cost check, by ToPage and ToPageSize for the connections and by the comment service
+// for the thread, so such a query fails there with status_code 400 instead of costing nothing.
+func multiply(size int, childComplexity int) int {
+	size = max(size, 1)
+	if childComplexity > 0 && size > maxComplexity/childComplexity {
+		return maxComplexity
+	}
+
+	return size * childComplexity
+}
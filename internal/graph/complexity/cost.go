@@ -0,0 +1,30 @@
+package complexity
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+)
+
+// Cost returns the calculated complexity and depth of the operation next to their limits,
+// a value is missing when the operation was rejected before it was calculated.
+func Cost(ctx context.Context) map[string]int {
+	if !graphql.HasOperationContext(ctx) {
+		return nil
+	}
+	cost := make(map[string]int)
+	if stats := extension.GetComplexityStats(ctx); stats != nil {
+		cost["complexity"] = stats.Complexity
+		cost["maxComplexity"] = stats.ComplexityLimit
+	}
+	if stats := GetDepthStats(ctx); stats != nil {
+		cost["depth"] = stats.Depth
+		cost["maxDepth"] = stats.DepthLimit
+	}
+	if len(cost) == 0 {
+		return nil
+	}
+
+	return cost
+}
@@ -0,0 +1,100 @@
+package complexity
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	depthExtension = "DepthLimit"
+	errDepthLimit  = "DEPTH_LIMIT_EXCEEDED"
+)
+
+// DepthLimit rejects operations nested deeper than the limit, the way extension.ComplexityLimit
+// rejects complex ones. Introspection fields are not counted.
+type DepthLimit struct {
+	Limit int
+}
+
+type DepthStats struct {
+	Depth      int
+	DepthLimit int
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = DepthLimit{}
+
+func (DepthLimit) ExtensionName() string {
+	return depthExtension
+}
+
+func (d DepthLimit) Validate(schema graphql.ExecutableSchema) error {
+	if d.Limit < 1 {
+		return errors.New("DepthLimit limit must be positive")
+	}
+
+	return nil
+}
+
+func (d DepthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op == nil {
+		return nil
+	}
+	depth := selectionDepth(op.SelectionSet, rc.Doc.Fragments, make(map[string]bool))
+	rc.Stats.SetExtension(depthExtension, &DepthStats{
+		Depth:      depth,
+		DepthLimit: d.Limit,
+	})
+	if depth > d.Limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Limit)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+
+	return nil
+}
+
+func GetDepthStats(ctx context.Context) *DepthStats {
+	if !graphql.HasOperationContext(ctx) {
+		return nil
+	}
+	stats, _ := graphql.GetOperationContext(ctx).Stats.GetExtension(depthExtension).(*DepthStats)
+
+	return stats
+}
+
+// selectionDepth counts the fields on the longest path, fragments add no level of their own.
+// visiting guards against fragment cycles, they are rejected by the validation anyway.
+func selectionDepth(set ast.SelectionSet, fragments ast.FragmentDefinitionList, visiting map[string]bool) int {
+	depth := 0
+	for _, selection := range set {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name, "__") {
+				continue
+			}
+			depth = max(depth, 1+selectionDepth(selection.SelectionSet, fragments, visiting))
+		case *ast.InlineFragment:
+			depth = max(depth, selectionDepth(selection.SelectionSet, fragments, visiting))
+		case *ast.FragmentSpread:
+			fragment := fragments.ForName(selection.Name)
+			if fragment == nil || visiting[selection.Name] {
+				continue
+			}
+			visiting[selection.Name] = true
+			depth = max(depth, selectionDepth(fragment.SelectionSet, fragments, visiting))
+			delete(visiting, selection.Name)
+		}
+	}
+
+	return depth
+}
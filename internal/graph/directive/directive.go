@@ -0,0 +1,130 @@
+package directive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	gqlconv "github.com/elusiv0/oz_task/internal/converter/gql"
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/graph"
+	"github.com/elusiv0/oz_task/internal/middleware"
+	"github.com/elusiv0/oz_task/internal/repo"
+	"github.com/elusiv0/oz_task/internal/service"
+)
+
+var (
+	UnauthenticatedErr = dto.ErrInfo{
+		ErrorMessage: "authentication required",
+		StatusCode:   http.StatusUnauthorized,
+	}
+	RoleForbiddenErr = dto.ErrInfo{
+		ErrorMessage: "not enough permissions for the operation",
+		StatusCode:   http.StatusForbidden,
+	}
+	NotOwnerErr = dto.ErrInfo{
+		ErrorMessage: "only the author or a moderator can modify it",
+		StatusCode:   http.StatusForbidden,
+	}
+)
+
+// roleRank orders roles, a caller satisfies every role ranked not higher than his own.
+var roleRank = map[graph.Role]int{
+	graph.RoleUser:      1,
+	graph.RoleModerator: 2,
+}
+
+type AuthFunc func(ctx context.Context, obj any, next graphql.Resolver, requires *graph.Role) (any, error)
+
+type OwnerFunc func(ctx context.Context, obj any, next graphql.Resolver) (any, error)
+
+// Auth implements @auth directive, the caller has to be authenticated
+// and to have a role not lower than required one.
+func Auth() AuthFunc {
+	return func(ctx context.Context, obj any, next graphql.Resolver, requires *graph.Role) (any, error) {
+		identity, ok := middleware.GetIdentity(ctx)
+		if !ok {
+			return nil, denied(ctx, UnauthenticatedErr)
+		}
+		required := graph.RoleUser
+		if requires != nil {
+			required = *requires
+		}
+		if roleRank[callerRole(identity)] < roleRank[required] {
+			return nil, denied(ctx, RoleForbiddenErr)
+		}
+
+		return next(ctx)
+	}
+}
+
+// Owner implements @owner directive. The entity is looked up by the id argument
+// of the field and its kind is taken from the field return type.
+// Moderators pass the check for any entity.
+func Owner(postService service.PostService, commentService service.CommentService) OwnerFunc {
+	return func(ctx context.Context, obj any, next graphql.Resolver) (any, error) {
+		identity, ok := middleware.GetIdentity(ctx)
+		if !ok {
+			return nil, denied(ctx, UnauthenticatedErr)
+		}
+		if callerRole(identity) == graph.RoleModerator {
+			return next(ctx)
+		}
+
+		fieldCtx := graphql.GetFieldContext(ctx)
+		id, ok := fieldCtx.Args["id"].(int)
+		if !ok {
+			return nil, fmt.Errorf("directive - Owner: field %s has no id argument", fieldCtx.Field.Name)
+		}
+
+		var authorId *int
+		switch entity := fieldCtx.Field.Definition.Type.Name(); entity {
+		case "Post":
+			post, err := postService.Get(ctx, id)
+			if err != nil {
+				return nil, notFound(ctx, err, repo.PostNotFoundErr, id)
+			}
+			authorId = post.AuthorID
+		case "Comment":
+			comment, err := commentService.Get(ctx, id)
+			if err != nil {
+				return nil, notFound(ctx, err, repo.CommentNotFoundErr, id)
+			}
+			authorId = comment.AuthorID
+		default:
+			return nil, fmt.Errorf("directive - Owner: unsupported entity %s", entity)
+		}
+
+		if authorId == nil || *authorId != identity.UserId {
+			return nil, denied(ctx, NotOwnerErr)
+		}
+
+		return next(ctx)
+	}
+}
+
+func callerRole(identity middleware.Identity) graph.Role {
+	role := graph.Role(strings.ToUpper(identity.Role))
+	if !role.IsValid() {
+		return graph.RoleUser
+	}
+
+	return role
+}
+
+func denied(ctx context.Context, errInfo dto.ErrInfo) error {
+	cErr := dto.NewCustomError(errInfo, graphql.GetFieldContext(ctx).Args)
+	return gqlconv.ToGqlError(ctx, cErr)
+}
+
+func notFound(ctx context.Context, err error, errInfo dto.ErrInfo, id int) error {
+	var cErr *dto.CustomError
+	if errors.As(err, &cErr) {
+		return gqlconv.ToGqlError(ctx, dto.NewCustomError(errInfo, id))
+	}
+
+	return err
+}
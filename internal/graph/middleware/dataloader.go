@@ -7,104 +7,321 @@ import (
 
 	"github.com/elusiv0/oz_task/internal/dto"
 	"github.com/elusiv0/oz_task/internal/graph/dataloader"
+	"github.com/elusiv0/oz_task/internal/metrics"
 	repo "github.com/elusiv0/oz_task/internal/repo"
 	"github.com/elusiv0/oz_task/internal/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	commentLoaderKey = "commentLoader"
+	commentLoaderKey      = "commentLoader"
+	commentByIdLoaderKey  = "commentByIdLoader"
+	commentCountLoaderKey = "commentCountLoader"
+	postLoaderKey         = "postLoader"
+	postCountLoaderKey    = "postCountLoader"
+	userLoaderKey         = "userLoader"
 )
 
-func DataloaderMiddleware(s service.CommentService, next http.Handler) http.Handler {
+func DataloaderMiddleware(s service.CommentService, ps service.PostService, us service.UserService, m *metrics.Metrics, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		commentLoaderConfig := dataloader.CommentLoaderConfig{
 			MaxBatch: 100,
 			Wait:     5 * time.Millisecond,
 			Fetch: func(commentReqs []dto.GetCommentsRequest) ([][]*dto.Comment, []error) {
-				mapReqIndex := make(map[int]int)
-				byParent := false
-				if commentReqs[0].ParentId != nil {
-					byParent = true
+				ctx, span := startBatch(r.Context(), m, "comment", len(commentReqs))
+				defer span.End()
+				errorsResp := make([]error, len(commentReqs))
+				commentsResp := make([][]*dto.Comment, len(commentReqs))
+				// sibling fields with other arguments are fetched by their own query
+				for _, batch := range splitBatches(commentReqs) {
+					batchReqs := make([]dto.GetCommentsRequest, len(batch))
+					for idx, reqIdx := range batch {
+						batchReqs[idx] = commentReqs[reqIdx]
+					}
+					batchComments, batchErrors := fetchComments(ctx, s, batchReqs)
+					for idx, reqIdx := range batch {
+						commentsResp[reqIdx] = batchComments[idx]
+						errorsResp[reqIdx] = batchErrors[idx]
+					}
+				}
+				return commentsResp, errorsResp
+			},
+		}
+		commentLoader := dataloader.NewCommentLoader(commentLoaderConfig)
+
+		commentByIdLoaderConfig := dataloader.CommentByIdLoaderConfig{
+			MaxBatch: 100,
+			Wait:     5 * time.Millisecond,
+			Fetch: func(ids []int) ([]*dto.Comment, []error) {
+				ctx, span := startBatch(r.Context(), m, "commentById", len(ids))
+				defer span.End()
+				errorsResp := make([]error, len(ids))
+				commentsResp := make([]*dto.Comment, len(ids))
+
+				commentsPool, err := s.GetByIds(ctx, ids...)
+				if err != nil {
+					span.RecordError(err)
+					for idx := range errorsResp {
+						errorsResp[idx] = err
+					}
+					return commentsResp, errorsResp
 				}
-				for idx, val := range commentReqs {
-					insertIdx := 0
-					if byParent {
-						insertIdx = *val.ParentId
-					} else {
-						insertIdx = *val.PostId
+				comments := make(map[int]*dto.Comment, len(commentsPool))
+				for _, val := range commentsPool {
+					comments[val.ID] = val
+				}
+
+				for idx, id := range ids {
+					comment, ok := comments[id]
+					if !ok {
+						errorsResp[idx] = dto.NewCustomError(repo.CommentNotFoundErr, id)
+						continue
 					}
-					mapReqIndex[insertIdx] = idx
+					commentsResp[idx] = comment
 				}
-				comments := make(map[int]bool)
+				return commentsResp, errorsResp
+			},
+		}
+		commentByIdLoader := dataloader.NewCommentByIdLoader(commentByIdLoaderConfig)
+
+		commentCountLoaderConfig := dataloader.CommentCountLoaderConfig{
+			MaxBatch: 100,
+			Wait:     5 * time.Millisecond,
+			Fetch: func(commentReqs []dto.GetCommentsRequest) ([]int, []error) {
+				ctx, span := startBatch(r.Context(), m, "commentCount", len(commentReqs))
+				defer span.End()
 				errorsResp := make([]error, len(commentReqs))
-				commentsResp := make([][]*dto.Comment, len(commentReqs))
+				countsResp, err := s.Count(ctx, commentReqs...)
+				if err != nil {
+					span.RecordError(err)
+					for idx := range errorsResp {
+						errorsResp[idx] = err
+					}
+				}
+				return countsResp, errorsResp
+			},
+		}
+		commentCountLoader := dataloader.NewCommentCountLoader(commentCountLoaderConfig)
 
-				commentsPool, err := s.GetMany(r.Context(), commentReqs...)
+		postLoaderConfig := dataloader.PostLoaderConfig{
+			MaxBatch: 100,
+			Wait:     5 * time.Millisecond,
+			Fetch: func(postReqs []dto.GetPostsRequest) ([][]*dto.Post, []error) {
+				ctx, span := startBatch(r.Context(), m, "post", len(postReqs))
+				defer span.End()
+				errorsResp := make([]error, len(postReqs))
+				postsResp := make([][]*dto.Post, len(postReqs))
+				for _, batch := range splitBatches(postReqs) {
+					batchReqs := make([]dto.GetPostsRequest, len(batch))
+					for idx, reqIdx := range batch {
+						batchReqs[idx] = postReqs[reqIdx]
+					}
+					batchPosts, batchErrors := fetchPosts(ctx, ps, batchReqs)
+					for idx, reqIdx := range batch {
+						postsResp[reqIdx] = batchPosts[idx]
+						errorsResp[reqIdx] = batchErrors[idx]
+					}
+				}
+				return postsResp, errorsResp
+			},
+		}
+		postLoader := dataloader.NewPostLoader(postLoaderConfig)
+
+		postCountLoaderConfig := dataloader.PostCountLoaderConfig{
+			MaxBatch: 100,
+			Wait:     5 * time.Millisecond,
+			Fetch: func(postReqs []dto.GetPostsRequest) ([]int, []error) {
+				ctx, span := startBatch(r.Context(), m, "postCount", len(postReqs))
+				defer span.End()
+				errorsResp := make([]error, len(postReqs))
+				countsResp, err := ps.Count(ctx, postReqs...)
 				if err != nil {
-					for idx, _ := range errorsResp {
+					span.RecordError(err)
+					for idx := range errorsResp {
 						errorsResp[idx] = err
 					}
-					return commentsResp, errorsResp
 				}
-				last := 0
-				prev := -1
-				cplen := len(commentsPool)
-
-				for idx := 0; idx < cplen; idx++ {
-					val := commentsPool[idx]
-					if byParent {
-						_, ok := comments[*val.ParentID]
-						if !ok && idx != 0 {
-							insertIdx := idx
-
-							prev = *(commentsPool[insertIdx-1].ParentID)
-							commentsResp[mapReqIndex[prev]] = commentsPool[last:insertIdx]
-							last = idx
-						}
-						if idx+1 == cplen {
-							commentsResp[mapReqIndex[*val.ParentID]] = commentsPool[last:]
-						}
-						if ok {
-							continue
-						}
-						comments[*val.ParentID] = true
-					} else {
-						_, ok := comments[val.ArticleID]
-						if !ok && idx != 0 {
-							insertIdx := idx
-							if idx+1 == cplen {
-								insertIdx++
-							}
-
-							prev = commentsPool[insertIdx-1].ArticleID
-							commentsResp[mapReqIndex[prev]] = commentsPool[last:insertIdx]
-							last = idx
-						}
-						if idx+1 == cplen {
-							commentsResp[mapReqIndex[val.ArticleID]] = commentsPool[last:]
-						}
-						if ok {
-							continue
-						}
-						comments[val.ArticleID] = true
+				return countsResp, errorsResp
+			},
+		}
+		postCountLoader := dataloader.NewPostCountLoader(postCountLoaderConfig)
+
+		userLoaderConfig := dataloader.UserLoaderConfig{
+			MaxBatch: 100,
+			Wait:     5 * time.Millisecond,
+			Fetch: func(ids []int) ([]*dto.User, []error) {
+				ctx, span := startBatch(r.Context(), m, "user", len(ids))
+				defer span.End()
+				errorsResp := make([]error, len(ids))
+				usersResp := make([]*dto.User, len(ids))
+
+				usersPool, err := us.GetByIds(ctx, ids...)
+				if err != nil {
+					span.RecordError(err)
+					for idx := range errorsResp {
+						errorsResp[idx] = err
 					}
+					return usersResp, errorsResp
+				}
+				users := make(map[int]*dto.User, len(usersPool))
+				for _, val := range usersPool {
+					users[val.ID] = val
 				}
 
-				for idx, val := range commentsResp {
-					if len(val) == 0 {
-						errorsResp[idx] = dto.NewCustomError(repo.CommentsNotFoundErr, commentReqs[idx])
+				for idx, id := range ids {
+					user, ok := users[id]
+					if !ok {
+						errorsResp[idx] = dto.NewCustomError(repo.UserNotFoundErr, id)
+						continue
 					}
+					usersResp[idx] = user
 				}
-				return commentsResp, errorsResp
+				return usersResp, errorsResp
 			},
 		}
-		commentLoader := dataloader.NewCommentLoader(commentLoaderConfig)
+		userLoader := dataloader.NewUserLoader(userLoaderConfig)
 
 		ctx := context.WithValue(r.Context(), commentLoaderKey, commentLoader)
+		ctx = context.WithValue(ctx, commentByIdLoaderKey, commentByIdLoader)
+		ctx = context.WithValue(ctx, commentCountLoaderKey, commentCountLoader)
+		ctx = context.WithValue(ctx, postLoaderKey, postLoader)
+		ctx = context.WithValue(ctx, postCountLoaderKey, postCountLoader)
+		ctx = context.WithValue(ctx, userLoaderKey, userLoader)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// splitBatches groups the indexes of the requests by their BatchKey keeping their order.
+func splitBatches[T interface{ BatchKey() string }](reqs []T) [][]int {
+	batches := [][]int{}
+	byKey := make(map[string]int)
+	for idx, val := range reqs {
+		key := val.BatchKey()
+		batchIdx, ok := byKey[key]
+		if !ok {
+			batchIdx = len(batches)
+			byKey[key] = batchIdx
+			batches = append(batches, []int{})
+		}
+		batches[batchIdx] = append(batches[batchIdx], idx)
+	}
+
+	return batches
+}
+
+// fetchComments loads the requests of one batch and groups the comments by author, post or parent.
+func fetchComments(ctx context.Context, s service.CommentService, commentReqs []dto.GetCommentsRequest) ([][]*dto.Comment, []error) {
+	byAuthor := commentReqs[0].AuthorId != nil
+	byParent := false
+	if commentReqs[0].ParentId != nil {
+		byParent = true
+	}
+	errorsResp := make([]error, len(commentReqs))
+	commentsResp := make([][]*dto.Comment, len(commentReqs))
+
+	commentsPool, err := s.GetMany(ctx, commentReqs...)
+	if err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+		for idx, _ := range errorsResp {
+			errorsResp[idx] = err
+		}
+		return commentsResp, errorsResp
+	}
+
+	// comments keep the order of the repo inside of their group
+	grouped := make(map[int][]*dto.Comment)
+	for _, val := range commentsPool {
+		groupId := val.ArticleID
+		if byAuthor {
+			groupId = *val.AuthorID
+		} else if byParent {
+			groupId = *val.ParentID
+		}
+		grouped[groupId] = append(grouped[groupId], val)
+	}
+
+	for idx, val := range commentReqs {
+		groupId := 0
+		if byAuthor {
+			groupId = *val.AuthorId
+		} else if byParent {
+			groupId = *val.ParentId
+		} else {
+			groupId = *val.PostId
+		}
+		commentsResp[idx] = grouped[groupId]
+		if len(commentsResp[idx]) == 0 {
+			errorsResp[idx] = dto.NewCustomError(repo.CommentsNotFoundErr, commentReqs[idx])
+		}
+	}
+	return commentsResp, errorsResp
+}
+
+// fetchPosts loads the requests of one batch and groups the posts by author. The loader serves
+// the posts of the authors, a batch of requests without an author is rejected by the repo.
+func fetchPosts(ctx context.Context, ps service.PostService, postReqs []dto.GetPostsRequest) ([][]*dto.Post, []error) {
+	errorsResp := make([]error, len(postReqs))
+	postsResp := make([][]*dto.Post, len(postReqs))
+
+	postsPool, err := ps.GetMany(ctx, postReqs...)
+	if err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+		for idx := range errorsResp {
+			errorsResp[idx] = err
+		}
+		return postsResp, errorsResp
+	}
+	if postReqs[0].AuthorId == nil {
+		postsResp[0] = postsPool
+		return postsResp, errorsResp
+	}
+
+	// posts keep the order of the repo inside of their group
+	grouped := make(map[int][]*dto.Post)
+	for _, val := range postsPool {
+		grouped[*val.AuthorID] = append(grouped[*val.AuthorID], val)
+	}
+	for idx, val := range postReqs {
+		postsResp[idx] = grouped[*val.AuthorId]
+		if len(postsResp[idx]) == 0 {
+			errorsResp[idx] = dto.NewCustomError(repo.PostsNotFoundErr, postReqs[idx])
+		}
+	}
+	return postsResp, errorsResp
+}
+
 func GetCommentLoader(ctx context.Context) *dataloader.CommentLoader {
 	return ctx.Value(commentLoaderKey).(*dataloader.CommentLoader)
 }
+
+func GetCommentByIdLoader(ctx context.Context) *dataloader.CommentByIdLoader {
+	return ctx.Value(commentByIdLoaderKey).(*dataloader.CommentByIdLoader)
+}
+
+func GetCommentCountLoader(ctx context.Context) *dataloader.CommentCountLoader {
+	return ctx.Value(commentCountLoaderKey).(*dataloader.CommentCountLoader)
+}
+
+func GetPostLoader(ctx context.Context) *dataloader.PostLoader {
+	return ctx.Value(postLoaderKey).(*dataloader.PostLoader)
+}
+
+func GetPostCountLoader(ctx context.Context) *dataloader.PostCountLoader {
+	return ctx.Value(postCountLoaderKey).(*dataloader.PostCountLoader)
+}
+
+func GetUserLoader(ctx context.Context) *dataloader.UserLoader {
+	return ctx.Value(userLoaderKey).(*dataloader.UserLoader)
+}
+
+// startBatch observes the size of the batch and starts its span. Loaders fetch outside of
+// the resolvers, so the span is a child of the request span rather than of a field.
+func startBatch(ctx context.Context, m *metrics.Metrics, loader string, size int) (context.Context, trace.Span) {
+	m.ObserveBatch(loader, size)
+
+	return tracer.Start(ctx, "dataloader "+loader, trace.WithAttributes(
+		attribute.String("dataloader.name", loader),
+		attribute.Int("dataloader.batch_size", size),
+	))
+}
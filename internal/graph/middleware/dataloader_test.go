@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/metrics"
+	"github.com/elusiv0/oz_task/internal/middleware"
+	imCommentRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/comment"
+	"github.com/elusiv0/oz_task/internal/service"
+	commentService "github.com/elusiv0/oz_task/internal/service/comment"
+	"github.com/gin-gonic/gin"
+)
+
+// countingService records the batches counted by the comment service.
+type countingService struct {
+	service.CommentService
+	mu      sync.Mutex
+	batches [][]dto.GetCommentsRequest
+}
+
+func (c *countingService) Count(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]int, error) {
+	c.mu.Lock()
+	c.batches = append(c.batches, commentsReq)
+	c.mu.Unlock()
+
+	return c.CommentService.Count(ctx, commentsReq...)
+}
+
+// TestCommentCountLoader counts the replies of a page of comments and checks they are counted by one batch.
+func TestCommentCountLoader(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	commentRepo := imCommentRepo.New(logger)
+	postId := 3001
+	parents := []int{}
+	for replies := range 3 {
+		parent, err := commentRepo.Insert(ctx, dto.NewComment{Text: "text", ArticleID: postId})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range replies {
+			if _, err := commentRepo.Insert(ctx, dto.NewComment{Text: "text", ArticleID: postId, ParentID: &parent.ID}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		parents = append(parents, parent.ID)
+	}
+	cs := &countingService{CommentService: commentService.New(commentRepo, 32, logger)}
+
+	counts := make([]int, len(parents))
+	handler := DataloaderMiddleware(cs, nil, nil, metrics.New(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loader := GetCommentCountLoader(r.Context())
+		wg := sync.WaitGroup{}
+		for idx, parent := range parents {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				count, err := loader.Load(dto.GetCommentsRequest{ParentId: &parent})
+				if err != nil {
+					t.Error(err)
+				}
+				counts[idx] = count
+			}()
+		}
+		wg.Wait()
+	}))
+	// the services log the id of the request set by the router
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestMiddleware())
+	router.POST("/query", gin.WrapH(handler))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil))
+
+	for idx, count := range counts {
+		if count != idx {
+			t.Errorf("comment %d: got %d replies, want %d", parents[idx], count, idx)
+		}
+	}
+	if len(cs.batches) != 1 || len(cs.batches[0]) != len(parents) {
+		t.Errorf("got batches %v, want one batch of %d", cs.batches, len(parents))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/elusiv0/oz_task/internal/graph/complexity"
 	"github.com/elusiv0/oz_task/internal/middleware"
 )
 
@@ -15,10 +16,15 @@ const (
 func ResponseMiddleware(logger *slog.Logger) graphql.ResponseMiddleware {
 	return func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
 		resp := next(ctx)
+		if resp == nil {
+			// subscription stream was completed
+			return nil
+		}
 		reqUuid := middleware.GetUuid(ctx)
 		logger := logger.With(
 			slog.String("request_id", reqUuid),
 			slog.Any("path", graphql.GetPath(ctx).String()),
+			slog.String("trace_id", middleware.GetTraceId(ctx)),
 		)
 		status := "success"
 		level := slog.LevelInfo
@@ -30,6 +36,12 @@ func ResponseMiddleware(logger *slog.Logger) graphql.ResponseMiddleware {
 		resp.Extensions = map[string]any{
 			"request_id": reqUuid,
 		}
+		if traceId := middleware.GetTraceId(ctx); traceId != "" {
+			resp.Extensions["trace_id"] = traceId
+		}
+		if cost := complexity.Cost(ctx); cost != nil {
+			resp.Extensions["cost"] = cost
+		}
 		return resp
 	}
 }
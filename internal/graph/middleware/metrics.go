@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/elusiv0/oz_task/internal/metrics"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const anonymousOperation = "anonymous"
+
+// MetricsExtension counts GraphQL operations, resolver fields and errors.
+type MetricsExtension struct {
+	Metrics *metrics.Metrics
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = MetricsExtension{}
+
+func (MetricsExtension) ExtensionName() string {
+	return "Metrics"
+}
+
+func (MetricsExtension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e MetricsExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil || !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+	oc := graphql.GetOperationContext(ctx)
+	name, operationType := operationInfo(oc)
+	name = e.Metrics.OperationLabel(name)
+
+	status := "success"
+	if len(resp.Errors) > 0 {
+		status = "error"
+	}
+	for _, err := range resp.Errors {
+		code := "unknown"
+		if statusCode, ok := err.Extensions["status_code"]; ok {
+			code = fmt.Sprint(statusCode)
+		} else if statusCode, ok := err.Extensions["status"]; ok {
+			// internal errors of the resolvers
+			code = fmt.Sprint(statusCode)
+		}
+		e.Metrics.Errors.WithLabelValues(code).Inc()
+	}
+	e.Metrics.Operations.WithLabelValues(name, operationType, status).Inc()
+	// an event of a subscription has no meaningful duration
+	if operationType != string(ast.Subscription) {
+		e.Metrics.OperationDuration.WithLabelValues(name, operationType).
+			Observe(time.Since(oc.Stats.OperationStart).Seconds())
+	}
+
+	return resp
+}
+
+// InterceptField measures only fields backed by a resolver, the rest are plain struct reads.
+func (e MetricsExtension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+	start := time.Now()
+	res, err := next(ctx)
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	e.Metrics.Fields.WithLabelValues(fc.Object, fc.Field.Name, status).Inc()
+	e.Metrics.FieldDuration.WithLabelValues(fc.Object, fc.Field.Name).Observe(time.Since(start).Seconds())
+
+	return res, err
+}
+
+// operationInfo returns the name and the type of the operation, the name of the document
+// is used when the client didn't send operationName.
+func operationInfo(oc *graphql.OperationContext) (name string, operationType string) {
+	name = oc.OperationName
+	operationType = "unknown"
+	if oc.Operation != nil {
+		name = oc.Operation.Name
+		operationType = string(oc.Operation.Operation)
+	}
+	if name == "" {
+		name = anonymousOperation
+	}
+
+	return name, operationType
+}
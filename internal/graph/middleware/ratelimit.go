@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/elusiv0/oz_task/internal/middleware"
+	"github.com/elusiv0/oz_task/pkg/ratelimit"
+)
+
+// RateLimitExtension limits the fields a client could flood others with:
+// writing posts and comments and opening subscriptions.
+type RateLimitExtension struct {
+	Limits *middleware.RateLimits
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = RateLimitExtension{}
+
+func (RateLimitExtension) ExtensionName() string {
+	return "RateLimit"
+}
+
+func (RateLimitExtension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e RateLimitExtension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil {
+		return next(ctx)
+	}
+	var limiter *ratelimit.Limiter
+	switch fc.Object + "." + fc.Field.Name {
+	case "Mutation.createComment", "Mutation.createPost":
+		limiter = e.Limits.Writes
+	case "Subscription.newComments":
+		limiter = e.Limits.Subscriptions
+	default:
+		return next(ctx)
+	}
+
+	if ok, retryAfter := limiter.Allow(middleware.ClientKey(ctx)); !ok {
+		err := middleware.RateLimitedError(retryAfter)
+		err.Path = graphql.GetPath(ctx)
+		return nil, err
+	}
+
+	return next(ctx)
+}
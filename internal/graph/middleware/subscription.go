@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type closeReasonKey struct{}
+
+type closeReason struct {
+	mu   sync.Mutex
+	err  *gqlerror.Error
+	sent bool
+}
+
+// SubscriptionCloseExtension sends the reason a subscription was completed by the server as its last event,
+// the transports complete a subscription without any payload otherwise.
+type SubscriptionCloseExtension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.ResponseInterceptor
+} = SubscriptionCloseExtension{}
+
+func (SubscriptionCloseExtension) ExtensionName() string {
+	return "SubscriptionClose"
+}
+
+func (SubscriptionCloseExtension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (SubscriptionCloseExtension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation != ast.Subscription {
+		return next(ctx)
+	}
+
+	return next(context.WithValue(ctx, closeReasonKey{}, &closeReason{}))
+}
+
+// InterceptResponse turns the end of the event stream into one more event carrying the reason,
+// the stream ends on the next call since the channel of the resolver stays closed.
+func (SubscriptionCloseExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp != nil {
+		return resp
+	}
+	reason, ok := ctx.Value(closeReasonKey{}).(*closeReason)
+	if !ok {
+		return nil
+	}
+	reason.mu.Lock()
+	defer reason.mu.Unlock()
+	if reason.err == nil || reason.sent {
+		return nil
+	}
+	reason.sent = true
+
+	return &graphql.Response{Errors: gqlerror.List{reason.err}}
+}
+
+// SetCloseReason records why the server completes the subscription of the context,
+// it has to be called before the channel of the subscription is closed.
+func SetCloseReason(ctx context.Context, err *gqlerror.Error) {
+	reason, ok := ctx.Value(closeReasonKey{}).(*closeReason)
+	if !ok {
+		return
+	}
+	reason.mu.Lock()
+	reason.err = err
+	reason.mu.Unlock()
+}
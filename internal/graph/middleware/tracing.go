@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/elusiv0/oz_task/internal/middleware"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/elusiv0/oz_task/internal/graph")
+
+// TracingExtension starts a span per operation and per field backed by a resolver.
+type TracingExtension struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.FieldInterceptor
+} = TracingExtension{}
+
+func (TracingExtension) ExtensionName() string {
+	return "Tracing"
+}
+
+func (TracingExtension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation ends the span of a query or a mutation with its response,
+// the span of a subscription lasts until the stream is completed.
+func (TracingExtension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	name, operationType := operationInfo(graphql.GetOperationContext(ctx))
+	ctx, span := tracer.Start(ctx, operationType+" "+name, trace.WithAttributes(
+		attribute.String("graphql.operation.name", name),
+		attribute.String("graphql.operation.type", operationType),
+		attribute.String("request_id", middleware.GetUuid(ctx)),
+	))
+	responses := next(ctx)
+
+	return func(ctx context.Context) *graphql.Response {
+		resp := responses(ctx)
+		if resp == nil {
+			span.End()
+			return nil
+		}
+		for _, err := range resp.Errors {
+			span.RecordError(err)
+		}
+		if len(resp.Errors) > 0 {
+			span.SetStatus(codes.Error, resp.Errors[0].Message)
+		}
+		if operationType != string(ast.Subscription) {
+			span.End()
+		}
+		return resp
+	}
+}
+
+func (TracingExtension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+	ctx, span := tracer.Start(ctx, fc.Object+"."+fc.Field.Name, trace.WithAttributes(
+		attribute.String("graphql.field.path", fc.Path().String()),
+	))
+	defer span.End()
+
+	res, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return res, err
+}
@@ -3,40 +3,158 @@
 package graph
 
 import (
+	"fmt"
+	"io"
+	"strconv"
+
 	"github.com/elusiv0/oz_task/internal/dto"
 )
 
 type CommentConnection struct {
-	Edges    []*CommentEdge `json:"edges"`
-	PageInfo *PageInfo      `json:"pageInfo"`
+	Edges      []*CommentEdge `json:"edges"`
+	PageInfo   *PageInfo      `json:"pageInfo"`
+	TotalCount int            `json:"totalCount"`
 }
 
 type CommentEdge struct {
 	Node   *dto.Comment `json:"node,omitempty"`
-	Cursor int          `json:"cursor"`
+	Cursor string       `json:"cursor"`
 }
 
 type Mutation struct {
 }
 
 type PageInfo struct {
-	StartCursor int   `json:"startCursor"`
-	EndCursor   int   `json:"endCursor"`
-	HasNextPage *bool `json:"hasNextPage,omitempty"`
+	StartCursor     string `json:"startCursor"`
+	EndCursor       string `json:"endCursor"`
+	HasNextPage     *bool  `json:"hasNextPage,omitempty"`
+	HasPreviousPage *bool  `json:"hasPreviousPage,omitempty"`
 }
 
 type PostConnection struct {
-	Edges    []*PostEdge `json:"edges"`
-	PageInfo *PageInfo   `json:"pageInfo"`
+	Edges      []*PostEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
 }
 
 type PostEdge struct {
 	Node   *dto.Post `json:"node,omitempty"`
-	Cursor int       `json:"cursor"`
+	Cursor string    `json:"cursor"`
 }
 
 type Query struct {
 }
 
+type SearchConnection struct {
+	Edges    []*SearchEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type SearchEdge struct {
+	Node   *dto.SearchResult `json:"node"`
+	Cursor string            `json:"cursor"`
+}
+
 type Subscription struct {
 }
+
+type Thread struct {
+	Nodes     []*dto.ThreadNode `json:"nodes"`
+	Truncated bool              `json:"truncated"`
+}
+
+type UserConnection struct {
+	Edges    []*UserEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type UserEdge struct {
+	Node   *dto.User `json:"node,omitempty"`
+	Cursor string    `json:"cursor"`
+}
+
+type Order string
+
+const (
+	OrderNewest         Order = "NEWEST"
+	OrderOldest         Order = "OLDEST"
+	OrderMostReplies    Order = "MOST_REPLIES"
+	OrderRecentActivity Order = "RECENT_ACTIVITY"
+)
+
+var AllOrder = []Order{
+	OrderNewest,
+	OrderOldest,
+	OrderMostReplies,
+	OrderRecentActivity,
+}
+
+func (e Order) IsValid() bool {
+	switch e {
+	case OrderNewest, OrderOldest, OrderMostReplies, OrderRecentActivity:
+		return true
+	}
+	return false
+}
+
+func (e Order) String() string {
+	return string(e)
+}
+
+func (e *Order) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Order(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Order", str)
+	}
+	return nil
+}
+
+func (e Order) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Role string
+
+const (
+	RoleUser      Role = "USER"
+	RoleModerator Role = "MODERATOR"
+)
+
+var AllRole = []Role{
+	RoleUser,
+	RoleModerator,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleUser, RoleModerator:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
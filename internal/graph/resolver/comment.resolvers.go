@@ -15,13 +15,40 @@ import (
 	"github.com/elusiv0/oz_task/internal/middleware"
 )
 
+// Author is the resolver for the author field.
+func (r *commentResolver) Author(ctx context.Context, obj *model.Comment) (*model.User, error) {
+	if obj.AuthorID == nil {
+		return nil, nil
+	}
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user loader...")
+	userResp, err := gqlmiddleware.GetUserLoader(ctx).Load(*obj.AuthorID)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "commentResolver - Author: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return userResp, nil
+}
+
 // Comments is the resolver for the comments field.
-func (r *commentResolver) Comments(ctx context.Context, obj *model.Comment, first *int, after *int) (*graph.CommentConnection, error) {
+func (r *commentResolver) Comments(ctx context.Context, obj *model.Comment, first *int, after *string, last *int, before *string, orderBy *graph.Order) (*graph.CommentConnection, error) {
 	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
+	logger.Debug("decoding page...")
+	page, err := gqlconv.ToPage(first, after, last, before, gqlconv.ToOrder(orderBy))
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "commentResolver - Comments: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
 	logger.Debug("wrapping comment request to dto...")
 	commentsReq := gqlconv.ToGetCommentsRequest(
-		gqlconv.WithCommentsPagination(*first, after),
+		gqlconv.WithCommentsPagination(page),
+		gqlconv.WithCommentsOrder(orderBy),
 		gqlconv.WithParentId(&obj.ID),
 	)
 
@@ -34,11 +61,89 @@ func (r *commentResolver) Comments(ctx context.Context, obj *model.Comment, firs
 	}
 
 	logger.Debug("converting comment response to post connection...")
-	commentConn := gqlconv.ToCommentConnection(commentsResp, commentsReq.First, commentsReq.After)
+	commentConn := gqlconv.ToCommentConnection(commentsResp, commentsReq.Page)
+
+	logger.Debug("counting total count of connection...")
+	commentConn.TotalCount, err = countComments(ctx, commentsReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "commentResolver - Comments: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
 
 	return commentConn, nil
 }
 
+// ReplyCount is the resolver for the replyCount field.
+func (r *commentResolver) ReplyCount(ctx context.Context, obj *model.Comment) (int, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment count loader...")
+	replyCount, err := gqlmiddleware.GetCommentCountLoader(ctx).Load(gqlconv.ToGetCommentsRequest(
+		gqlconv.WithParentId(&obj.ID),
+	))
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "commentResolver - ReplyCount: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return 0, gqlErr
+	}
+
+	return replyCount, nil
+}
+
+// Ancestors is the resolver for the ancestors field.
+func (r *commentResolver) Ancestors(ctx context.Context, obj *model.Comment) ([]*model.Comment, error) {
+	if len(obj.Path) == 0 {
+		return []*model.Comment{}, nil
+	}
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment loader...")
+	commentsResp, errs := gqlmiddleware.GetCommentByIdLoader(ctx).LoadAll(obj.Path)
+	for _, err := range errs {
+		if err != nil {
+			logger.Warn("Error was handled", slog.String("Cause", "commentResolver - Ancestors: "+err.Error()))
+			gqlErr := handleError(ctx, err)
+			return nil, gqlErr
+		}
+	}
+
+	return commentsResp, nil
+}
+
+// RootComment is the resolver for the rootComment field.
+func (r *commentResolver) RootComment(ctx context.Context, obj *model.Comment) (*model.Comment, error) {
+	if len(obj.Path) == 0 {
+		return obj, nil
+	}
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment loader...")
+	commentResp, err := gqlmiddleware.GetCommentByIdLoader(ctx).Load(obj.Path[0])
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "commentResolver - RootComment: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return commentResp, nil
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *commentResolver) Revisions(ctx context.Context, obj *model.Comment) ([]*model.CommentRevision, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment service...")
+	revisionsResp, err := r.commentService.GetRevisions(ctx, obj.ID)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "commentResolver - Revisions: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return revisionsResp, nil
+}
+
 // Comment returns graph.CommentResolver implementation.
 func (r *Resolver) Comment() graph.CommentResolver { return &commentResolver{r} }
 
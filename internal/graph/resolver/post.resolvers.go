@@ -15,13 +15,58 @@ import (
 	"github.com/elusiv0/oz_task/internal/middleware"
 )
 
+// ClosedBy is the resolver for the closedBy field.
+func (r *postResolver) ClosedBy(ctx context.Context, obj *model.Post) (*model.User, error) {
+	if obj.ClosedBy == nil {
+		return nil, nil
+	}
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user loader...")
+	userResp, err := gqlmiddleware.GetUserLoader(ctx).Load(*obj.ClosedBy)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "postResolver - ClosedBy: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return userResp, nil
+}
+
+// Author is the resolver for the author field.
+func (r *postResolver) Author(ctx context.Context, obj *model.Post) (*model.User, error) {
+	if obj.AuthorID == nil {
+		return nil, nil
+	}
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user loader...")
+	userResp, err := gqlmiddleware.GetUserLoader(ctx).Load(*obj.AuthorID)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "postResolver - Author: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return userResp, nil
+}
+
 // Comments is the resolver for the comments field.
-func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int, after *int) (*graph.CommentConnection, error) {
+func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int, after *string, last *int, before *string, orderBy *graph.Order) (*graph.CommentConnection, error) {
 	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
+	logger.Debug("decoding page...")
+	page, err := gqlconv.ToPage(first, after, last, before, gqlconv.ToOrder(orderBy))
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "postResolver - Comments: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
 	logger.Debug("wrapping comment request to dto...")
 	commentsReq := gqlconv.ToGetCommentsRequest(
-		gqlconv.WithCommentsPagination(*first, after),
+		gqlconv.WithCommentsPagination(page),
+		gqlconv.WithCommentsOrder(orderBy),
 		gqlconv.WithPostId(&obj.ID),
 	)
 
@@ -34,7 +79,15 @@ func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int
 	}
 
 	logger.Debug("converting comment response to post connection...")
-	commentConn := gqlconv.ToCommentConnection(commentsResp, commentsReq.First, commentsReq.After)
+	commentConn := gqlconv.ToCommentConnection(commentsResp, commentsReq.Page)
+
+	logger.Debug("counting total count of connection...")
+	commentConn.TotalCount, err = countComments(ctx, commentsReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "postResolver - Comments: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
 
 	return commentConn, nil
 }
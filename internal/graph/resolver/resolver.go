@@ -10,8 +10,13 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 	gqlconv "github.com/elusiv0/oz_task/internal/converter/gql"
 	model "github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/graph/directive"
+	gqlmiddleware "github.com/elusiv0/oz_task/internal/graph/middleware"
+	"github.com/elusiv0/oz_task/internal/metrics"
+	"github.com/elusiv0/oz_task/internal/middleware"
 	"github.com/elusiv0/oz_task/internal/service"
 	"github.com/elusiv0/oz_task/internal/util"
+	"github.com/google/uuid"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
@@ -24,14 +29,34 @@ var (
 		ErrorMessage: "post closed to add comments",
 		StatusCode:   http.StatusForbidden,
 	}
+	SubscriptionPostClosedErr = model.ErrInfo{
+		ErrorMessage: "post was closed to add comments, the subscription is completed",
+		StatusCode:   http.StatusGone,
+	}
+	SubscriptionPostDeletedErr = model.ErrInfo{
+		ErrorMessage: "post was deleted, the subscription is completed",
+		StatusCode:   http.StatusGone,
+	}
+	AuthorNotFoundErr = model.ErrInfo{
+		ErrorMessage: "couldn't get required author with provided id",
+		StatusCode:   http.StatusBadRequest,
+	}
 )
 
 type Resolver struct {
 	commentService   service.CommentService
 	postService      service.PostService
+	userService      service.UserService
+	searchService    service.SearchService
+	metrics          *metrics.Metrics
 	logger           *slog.Logger
-	postsSubscribers map[int]map[string]chan *model.Comment
-	mu               sync.Mutex
+	postsSubscribers map[int]map[string]*subscriber
+	mu               sync.RWMutex
+}
+
+type subscriber struct {
+	comments chan *model.Comment
+	ctx      context.Context
 }
 
 var customError *model.CustomError
@@ -39,13 +64,19 @@ var customError *model.CustomError
 func NewResolver(
 	commentService service.CommentService,
 	postService service.PostService,
+	userService service.UserService,
+	searchService service.SearchService,
+	metrics *metrics.Metrics,
 	logger *slog.Logger,
 ) *Resolver {
 	return &Resolver{
 		logger:           logger,
 		commentService:   commentService,
 		postService:      postService,
-		postsSubscribers: make(map[int]map[string]chan *model.Comment),
+		userService:      userService,
+		searchService:    searchService,
+		metrics:          metrics,
+		postsSubscribers: make(map[int]map[string]*subscriber),
 	}
 }
 
@@ -68,3 +99,128 @@ func handleError(ctx context.Context, err error) error {
 
 	return gqlErr
 }
+
+// subscribe checks the post and registers the subscriber under the lock closeSubscriptions takes,
+// so a post closed meanwhile either rejects the subscription or completes it.
+func (r *Resolver) subscribe(ctx context.Context, postID int) (<-chan *model.Comment, error) {
+	id := uuid.New().String()
+	sub := &subscriber{
+		comments: make(chan *model.Comment, 1),
+		ctx:      ctx,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	post, err := r.postService.Get(ctx, postID)
+	if err != nil {
+		var customErr *model.CustomError
+		if errors.As(err, &customErr) {
+			return nil, model.NewCustomError(CreateCommentPostNotFound, postID)
+		}
+		return nil, err
+	}
+	if post.Closed {
+		return nil, model.NewCustomError(CreateCommentPostClosedErr, postID)
+	}
+	if r.postsSubscribers[postID] == nil {
+		r.postsSubscribers[postID] = make(map[string]*subscriber)
+	}
+	r.postsSubscribers[postID][id] = sub
+	r.metrics.SetSubscriptions(postID, len(r.postsSubscribers[postID]))
+
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		// the post could be closed and reopened since, so the subscriber may be missing
+		if subscribers, ok := r.postsSubscribers[postID]; ok {
+			delete(subscribers, id)
+			r.metrics.SetSubscriptions(postID, len(subscribers))
+		}
+		r.mu.Unlock()
+	}()
+
+	return sub.comments, nil
+}
+
+func (r *Resolver) publish(comment *model.Comment) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, sub := range r.postsSubscribers[comment.ArticleID] {
+		select {
+		case sub.comments <- comment:
+		case <-sub.ctx.Done():
+		}
+	}
+}
+
+// closeSubscriptions completes every newComments subscription of the post since no comments
+// can be added anymore, subscribers get the reason as the last event before the complete message.
+func (r *Resolver) closeSubscriptions(postID int, reason *model.CustomError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.postsSubscribers[postID] {
+		gqlmiddleware.SetCloseReason(sub.ctx, gqlconv.ToGqlError(sub.ctx, reason))
+		close(sub.comments)
+	}
+	delete(r.postsSubscribers, postID)
+	r.metrics.SetSubscriptions(postID, 0)
+}
+
+func (r *Resolver) checkAuthor(ctx context.Context, authorID *int, req any) error {
+	if authorID == nil {
+		return nil
+	}
+	if _, err := r.userService.Get(ctx, *authorID); err != nil {
+		if errors.As(err, &customError) {
+			return model.NewCustomError(AuthorNotFoundErr, req)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func requireIdentity(ctx context.Context, req any) (middleware.Identity, error) {
+	identity, ok := middleware.GetIdentity(ctx)
+	if !ok {
+		return identity, model.NewCustomError(directive.UnauthenticatedErr, req)
+	}
+
+	return identity, nil
+}
+
+// fieldRequested reports whether the client selected the field on the object returned by the resolver.
+func fieldRequested(ctx context.Context, name string) bool {
+	for _, field := range graphql.CollectFieldsCtx(ctx, nil) {
+		if field.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// countComments loads the total count of the comments connection through the dataloader,
+// the count is loaded only when the client selected it.
+func countComments(ctx context.Context, commentsReq model.GetCommentsRequest) (int, error) {
+	if !fieldRequested(ctx, "totalCount") {
+		return 0, nil
+	}
+
+	return gqlmiddleware.GetCommentCountLoader(ctx).Load(gqlconv.ToGetCommentsRequest(
+		gqlconv.WithPostId(commentsReq.PostId),
+		gqlconv.WithParentId(commentsReq.ParentId),
+		gqlconv.WithCommentsAuthorId(commentsReq.AuthorId),
+	))
+}
+
+// countPosts loads the total count of the posts connection the same way as countComments.
+func countPosts(ctx context.Context, postsReq model.GetPostsRequest) (int, error) {
+	if !fieldRequested(ctx, "totalCount") {
+		return 0, nil
+	}
+
+	return gqlmiddleware.GetPostCountLoader(ctx).Load(gqlconv.ToGetPostsRequest(
+		gqlconv.WithPostsAuthorId(postsReq.AuthorId),
+	))
+}
@@ -13,14 +13,44 @@ import (
 	model "github.com/elusiv0/oz_task/internal/dto"
 	"github.com/elusiv0/oz_task/internal/graph"
 	"github.com/elusiv0/oz_task/internal/middleware"
-	"github.com/google/uuid"
 )
 
+// CreateUser is the resolver for the createUser field.
+func (r *mutationResolver) CreateUser(ctx context.Context, input model.NewUser) (*model.User, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user service...")
+	userResp, err := r.userService.Insert(ctx, input)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - CreateUser: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return userResp, nil
+}
+
 // CreatePost is the resolver for the createPost field.
 func (r *mutationResolver) CreatePost(ctx context.Context, input model.NewPost) (*model.Post, error) {
 	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
-	logger.Debug("calling comment service...")
+	logger.Debug("checking caller identity...")
+	identity, err := requireIdentity(ctx, input)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - CreatePost: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("checking post author...")
+	input.AuthorID = &identity.UserId
+	if err := r.checkAuthor(ctx, input.AuthorID, input); err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - CreatePost: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("calling post service...")
 	postResp, err := r.postService.Insert(ctx, input)
 	if err != nil {
 		r.logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - CreatePost: "+err.Error()))
@@ -35,6 +65,14 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input model.NewPost)
 func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewComment) (*model.Comment, error) {
 	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
+	logger.Debug("checking caller identity...")
+	identity, err := requireIdentity(ctx, input)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - CreateComment: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
 	logger.Debug("calling post service...")
 	post, err := r.postService.Get(ctx, input.ArticleID)
 	if err != nil {
@@ -53,6 +91,14 @@ func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewCom
 		return nil, gqlErr
 	}
 
+	logger.Debug("checking comment author...")
+	input.AuthorID = &identity.UserId
+	if err := r.checkAuthor(ctx, input.AuthorID, input); err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - CreateComment: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
 	logger.Debug("calling comment service...")
 	commentResp, err := r.commentService.Insert(ctx, input)
 	if err != nil {
@@ -62,20 +108,135 @@ func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewCom
 	}
 
 	logger.Debug("sending comment response to subscribe channel...")
-	for _, ch := range r.postsSubscribers[commentResp.ArticleID] {
-		ch <- commentResp
+	r.publish(commentResp)
+
+	return commentResp, nil
+}
+
+// UpdatePost is the resolver for the updatePost field.
+func (r *mutationResolver) UpdatePost(ctx context.Context, id int, input model.UpdatePost) (*model.Post, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling post service...")
+	postResp, err := r.postService.Update(ctx, id, input)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - UpdatePost: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return postResp, nil
+}
+
+// DeletePost is the resolver for the deletePost field.
+func (r *mutationResolver) DeletePost(ctx context.Context, id int) (*model.Post, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling post service...")
+	postResp, err := r.postService.Delete(ctx, id)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - DeletePost: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("closing post subscriptions...")
+	r.closeSubscriptions(postResp.ID, model.NewCustomError(SubscriptionPostDeletedErr, map[string]any{
+		"postId": postResp.ID,
+	}))
+
+	return postResp, nil
+}
+
+// ClosePost is the resolver for the closePost field.
+func (r *mutationResolver) ClosePost(ctx context.Context, id int) (*model.Post, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("checking caller identity...")
+	identity, err := requireIdentity(ctx, id)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - ClosePost: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("calling post service...")
+	postResp, err := r.postService.Close(ctx, id, identity.UserId)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - ClosePost: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("closing post subscriptions...")
+	r.closeSubscriptions(postResp.ID, model.NewCustomError(SubscriptionPostClosedErr, map[string]any{
+		"postId": postResp.ID, "closedBy": postResp.ClosedBy, "closedAt": postResp.ClosedAt,
+	}))
+
+	return postResp, nil
+}
+
+// ReopenPost is the resolver for the reopenPost field.
+func (r *mutationResolver) ReopenPost(ctx context.Context, id int) (*model.Post, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling post service...")
+	postResp, err := r.postService.Reopen(ctx, id)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - ReopenPost: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return postResp, nil
+}
+
+// EditComment is the resolver for the editComment field.
+func (r *mutationResolver) EditComment(ctx context.Context, id int, text string) (*model.Comment, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment service...")
+	commentResp, err := r.commentService.Edit(ctx, id, text)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - EditComment: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return commentResp, nil
+}
+
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, id int) (*model.Comment, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment service...")
+	commentResp, err := r.commentService.Delete(ctx, id)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "mutationResolver - DeleteComment: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
 	}
 
 	return commentResp, nil
 }
 
 // Posts is the resolver for the posts field.
-func (r *queryResolver) Posts(ctx context.Context, first *int, after *int) (*graph.PostConnection, error) {
+func (r *queryResolver) Posts(ctx context.Context, first *int, after *string, last *int, before *string, orderBy *graph.Order) (*graph.PostConnection, error) {
 	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
+	logger.Debug("decoding page...")
+	page, err := gqlconv.ToPage(first, after, last, before, gqlconv.ToOrder(orderBy))
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Posts: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
 	logger.Debug("wrapping post request to dto...")
 	postsReq := gqlconv.ToGetPostsRequest(
-		gqlconv.WithPostsPagination(*first, after),
+		gqlconv.WithPostsPagination(page),
+		gqlconv.WithPostsOrder(orderBy),
 	)
 
 	logger.Debug("calling post service...")
@@ -87,7 +248,15 @@ func (r *queryResolver) Posts(ctx context.Context, first *int, after *int) (*gra
 	}
 
 	logger.Debug("converting post response to post connection...")
-	postConn := gqlconv.ToPostConnection(postResp, postsReq.First, postsReq.After)
+	postConn := gqlconv.ToPostConnection(postResp, postsReq.Page)
+
+	logger.Debug("counting total count of connection...")
+	postConn.TotalCount, err = countPosts(ctx, postsReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Posts: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
 
 	return postConn, nil
 }
@@ -122,25 +291,131 @@ func (r *queryResolver) Comment(ctx context.Context, id *int) (*model.Comment, e
 	return commentResp, nil
 }
 
-// NewComments is the resolver for the newComments field.
-func (r *subscriptionResolver) NewComments(ctx context.Context, postID int) (<-chan *model.Comment, error) {
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, first *int, after *string) (*graph.UserConnection, error) {
 	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
-	id := uuid.New()
-	comments := make(chan *model.Comment, 1)
 
-	logger.Debug("initializing stop subscription goroutine...")
-	go func() {
-		<-ctx.Done()
-		r.mu.Lock()
-		delete(r.postsSubscribers[postID], id.String())
-		r.mu.Unlock()
-	}()
+	logger.Debug("decoding cursor...")
+	afterCursor, err := gqlconv.ToCursor(after, model.OrderNewest)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Users: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
 
-	if r.postsSubscribers[postID] == nil {
-		r.postsSubscribers[postID] = make(map[string]chan *model.Comment)
+	pageSize, err := gqlconv.ToPageSize(first)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Users: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
 	}
 
-	r.postsSubscribers[postID][id.String()] = comments
+	logger.Debug("wrapping user request to dto...")
+	usersReq := gqlconv.ToGetUsersRequest(
+		gqlconv.WithUsersPagination(pageSize, afterCursor),
+	)
+
+	logger.Debug("calling user service...")
+	usersResp, err := r.userService.GetMany(ctx, usersReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Users: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("converting user response to user connection...")
+	userConn := gqlconv.ToUserConnection(usersResp, usersReq.First, usersReq.After)
+
+	return userConn, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id int) (*model.User, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user service...")
+	userResp, err := r.userService.Get(ctx, id)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - User: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return userResp, nil
+}
+
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, query string, first *int, after *string) (*graph.SearchConnection, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("decoding cursor...")
+	afterCursor, err := gqlconv.ToCursor(after, model.OrderRelevance)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Search: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	pageSize, err := gqlconv.ToPageSize(first)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Search: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("wrapping search request to dto...")
+	searchReq := gqlconv.ToSearchRequest(query, pageSize, afterCursor)
+
+	logger.Debug("calling search service...")
+	searchResp, err := r.searchService.Search(ctx, searchReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Search: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("converting search response to search connection...")
+	searchConn := gqlconv.ToSearchConnection(searchResp, searchReq.First, searchReq.After)
+
+	return searchConn, nil
+}
+
+// Thread is the resolver for the thread field.
+func (r *queryResolver) Thread(ctx context.Context, commentID *int, postID *int, maxDepth *int, maxNodes *int) (*graph.Thread, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("wrapping thread request to dto...")
+	threadReq := gqlconv.ToThreadRequest(
+		commentID, postID,
+		gqlconv.ValueOr(maxDepth, gqlconv.DefaultThreadDepth),
+		gqlconv.ValueOr(maxNodes, gqlconv.DefaultThreadNodes),
+	)
+
+	logger.Debug("calling comment service...")
+	threadResp, err := r.commentService.Thread(ctx, threadReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "queryResolver - Thread: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("converting thread response to thread...")
+	thread := gqlconv.ToThread(threadResp, threadReq.MaxNodes)
+
+	return thread, nil
+}
+
+// NewComments is the resolver for the newComments field.
+func (r *subscriptionResolver) NewComments(ctx context.Context, postID int) (<-chan *model.Comment, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("subscribing to post comments...")
+	comments, err := r.subscribe(ctx, postID)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "subscriptionResolver - NewComments: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
 	logger.Debug("subscription is ready")
 
 	return comments, nil
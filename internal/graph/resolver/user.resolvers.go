@@ -0,0 +1,103 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.49
+
+import (
+	"context"
+	"log/slog"
+
+	gqlconv "github.com/elusiv0/oz_task/internal/converter/gql"
+	model "github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/graph"
+	gqlmiddleware "github.com/elusiv0/oz_task/internal/graph/middleware"
+	"github.com/elusiv0/oz_task/internal/middleware"
+)
+
+// Posts is the resolver for the posts field.
+func (r *userResolver) Posts(ctx context.Context, obj *model.User, first *int, after *string, last *int, before *string, orderBy *graph.Order) (*graph.PostConnection, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("decoding page...")
+	page, err := gqlconv.ToPage(first, after, last, before, gqlconv.ToOrder(orderBy))
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "userResolver - Posts: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("wrapping post request to dto...")
+	postsReq := gqlconv.ToGetPostsRequest(
+		gqlconv.WithPostsPagination(page),
+		gqlconv.WithPostsAuthorId(&obj.ID),
+		gqlconv.WithPostsOrder(orderBy),
+	)
+
+	logger.Debug("calling post service...")
+	postResp, err := gqlmiddleware.GetPostLoader(ctx).Load(postsReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "userResolver - Posts: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("converting post response to post connection...")
+	postConn := gqlconv.ToPostConnection(postResp, postsReq.Page)
+
+	logger.Debug("counting total count of connection...")
+	postConn.TotalCount, err = countPosts(ctx, postsReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "userResolver - Posts: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return postConn, nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *userResolver) Comments(ctx context.Context, obj *model.User, first *int, after *string, last *int, before *string, orderBy *graph.Order) (*graph.CommentConnection, error) {
+	logger := r.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("decoding page...")
+	page, err := gqlconv.ToPage(first, after, last, before, gqlconv.ToOrder(orderBy))
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "userResolver - Comments: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("wrapping comment request to dto...")
+	commentsReq := gqlconv.ToGetCommentsRequest(
+		gqlconv.WithCommentsPagination(page),
+		gqlconv.WithCommentsAuthorId(&obj.ID),
+		gqlconv.WithCommentsOrder(orderBy),
+	)
+
+	logger.Debug("calling comment service...")
+	commentsResp, err := gqlmiddleware.GetCommentLoader(ctx).Load(commentsReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "userResolver - Comments: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	logger.Debug("converting comment response to comment connection...")
+	commentConn := gqlconv.ToCommentConnection(commentsResp, commentsReq.Page)
+
+	logger.Debug("counting total count of connection...")
+	commentConn.TotalCount, err = countComments(ctx, commentsReq)
+	if err != nil {
+		logger.Warn("Error was handled", slog.String("Cause", "userResolver - Comments: "+err.Error()))
+		gqlErr := handleError(ctx, err)
+		return nil, gqlErr
+	}
+
+	return commentConn, nil
+}
+
+// User returns graph.UserResolver implementation.
+func (r *Resolver) User() graph.UserResolver { return &userResolver{r} }
+
+type userResolver struct{ *Resolver }
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "oz_task"
+
+	// otherOperation is the label of the operations missing from the known ones
+	otherOperation = "other"
+)
+
+type Metrics struct {
+	registry            *prometheus.Registry
+	operations          map[string]bool
+	Operations          *prometheus.CounterVec
+	OperationDuration   *prometheus.HistogramVec
+	Fields              *prometheus.CounterVec
+	FieldDuration       *prometheus.HistogramVec
+	Errors              *prometheus.CounterVec
+	ActiveSubscriptions *prometheus.GaugeVec
+	DataloaderBatch     *prometheus.HistogramVec
+}
+
+// New creates the metrics, operations are the names labeled as is. The name of an operation is set by
+// the client, so every other name is labeled as other to keep the count of series bounded.
+func New(operations ...string) *Metrics {
+	m := &Metrics{
+		registry:   prometheus.NewRegistry(),
+		operations: make(map[string]bool, len(operations)),
+		Operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_operations_total",
+			Help:      "Count of GraphQL responses by operation, subscriptions are counted per event.",
+		}, []string{"operation", "type", "status"}),
+		OperationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "graphql_operation_duration_seconds",
+			Help:      "Duration of GraphQL queries and mutations from the start of the operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "type"}),
+		Fields: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_fields_total",
+			Help:      "Count of resolved GraphQL fields backed by a resolver.",
+		}, []string{"object", "field", "status"}),
+		FieldDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "graphql_field_duration_seconds",
+			Help:      "Duration of GraphQL field resolvers.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"object", "field"}),
+		Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "graphql_errors_total",
+			Help:      "Count of GraphQL errors by the status_code extension.",
+		}, []string{"status_code"}),
+		ActiveSubscriptions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "graphql_active_subscriptions",
+			Help:      "Count of active newComments subscriptions by post.",
+		}, []string{"post_id"}),
+		DataloaderBatch: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "dataloader_batch_size",
+			Help:      "Count of keys fetched by a dataloader at once.",
+			Buckets:   []float64{1, 2, 5, 10, 25, 50, 100},
+		}, []string{"loader"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.Operations,
+		m.OperationDuration,
+		m.Fields,
+		m.FieldDuration,
+		m.Errors,
+		m.ActiveSubscriptions,
+		m.DataloaderBatch,
+	)
+
+	for _, operation := range operations {
+		m.operations[operation] = true
+	}
+
+	return m
+}
+
+// OperationLabel returns the label of the operation name.
+func (m *Metrics) OperationLabel(name string) string {
+	if m.operations[name] {
+		return name
+	}
+
+	return otherOperation
+}
+
+// RegisterPool adds stats of the postgres pool, they are read on every scrape.
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) error {
+	return m.registry.Register(newPoolCollector(pool))
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// SetSubscriptions sets the count of subscriptions of the post, the series is removed once it drops to zero.
+func (m *Metrics) SetSubscriptions(postID int, count int) {
+	label := strconv.Itoa(postID)
+	if count == 0 {
+		m.ActiveSubscriptions.DeleteLabelValues(label)
+		return
+	}
+	m.ActiveSubscriptions.WithLabelValues(label).Set(float64(count))
+}
+
+func (m *Metrics) ObserveBatch(loader string, size int) {
+	m.DataloaderBatch.WithLabelValues(loader).Observe(float64(size))
+}
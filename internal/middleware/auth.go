@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/elusiv0/oz_task/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	identityKey = "identity"
+
+	bearerPrefix = "Bearer "
+)
+
+type Identity struct {
+	UserId int
+	Role   string
+}
+
+func AuthMiddleware(verifier *auth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		ctx, err := Authenticate(c.Request.Context(), verifier, header)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"errors": []gin.H{{
+					"message": err.Error(),
+					"extensions": gin.H{
+						"status_code": http.StatusUnauthorized,
+					},
+				}},
+			})
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// Authenticate verifies the bearer token from the authorization value
+// and puts the identity of the caller into the context.
+func Authenticate(ctx context.Context, verifier *auth.Verifier, authorization string) (context.Context, error) {
+	token, ok := strings.CutPrefix(authorization, bearerPrefix)
+	if !ok {
+		return ctx, auth.ErrInvalidToken
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		return ctx, err
+	}
+
+	identity := Identity{
+		UserId: claims.UserId,
+		Role:   claims.Role,
+	}
+
+	return context.WithValue(ctx, identityKey, identity), nil
+}
+
+func GetIdentity(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey).(Identity)
+	return identity, ok
+}
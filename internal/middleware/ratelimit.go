@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elusiv0/oz_task/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	clientKey = "rateLimitClient"
+
+	apiKeyHeader = "X-Api-Key"
+)
+
+// RateLimits are the buckets of a client: every request takes a token of Requests,
+// Writes are taken by createComment and createPost, Subscriptions by opening newComments.
+// ApiKeys are the keys of the clients limited by key rather than by address.
+type RateLimits struct {
+	Requests      *ratelimit.Limiter
+	Writes        *ratelimit.Limiter
+	Subscriptions *ratelimit.Limiter
+	ApiKeys       []string
+}
+
+// RateLimitMiddleware limits the requests of the client, it must run after AuthMiddleware
+// so authenticated clients are limited by user rather than by api key or address.
+func RateLimitMiddleware(limits *RateLimits) gin.HandlerFunc {
+	// the buckets are keyed by the digests, so the keys themselves aren't kept with them
+	apiKeys := make(map[string]struct{}, len(limits.ApiKeys))
+	for _, key := range limits.ApiKeys {
+		apiKeys[digest(key)] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		// the address is taken from X-Forwarded-For only behind the trusted proxies of the engine
+		client := "ip:" + c.ClientIP()
+		// an unknown key is ignored, the client is limited by address then
+		if key := c.GetHeader(apiKeyHeader); key != "" {
+			keyDigest := digest(key)
+			if _, ok := apiKeys[keyDigest]; ok {
+				client = "key:" + keyDigest
+			}
+		}
+		ctx := context.WithValue(c.Request.Context(), clientKey, client)
+		c.Request = c.Request.WithContext(ctx)
+
+		if ok, retryAfter := limits.Requests.Allow(ClientKey(ctx)); !ok {
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(retryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"errors": []*gqlerror.Error{RateLimitedError(retryAfter)},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ClientKey identifies the caller for the rate limits: by the user when authenticated,
+// otherwise by the api key when it's a known one, and by the address at last. The identity is looked up on every call
+// since websocket connections are authenticated after the upgrade.
+func ClientKey(ctx context.Context) string {
+	if identity, ok := GetIdentity(ctx); ok {
+		return "user:" + strconv.Itoa(identity.UserId)
+	}
+	client, _ := ctx.Value(clientKey).(string)
+
+	return client
+}
+
+// RateLimitedError is the error of a rejected request, retryAfter is in whole seconds.
+func RateLimitedError(retryAfter time.Duration) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: "rate limit exceeded",
+		Extensions: map[string]any{
+			"status_code": http.StatusTooManyRequests,
+			"retryAfter":  retryAfterSeconds(retryAfter),
+		},
+	}
+}
+
+func digest(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func retryAfterSeconds(retryAfter time.Duration) int {
+	return int(math.Ceil(retryAfter.Seconds()))
+}
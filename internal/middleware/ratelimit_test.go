@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elusiv0/oz_task/pkg/auth"
+	"github.com/elusiv0/oz_task/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifier, err := auth.New(auth.HS256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "1", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		headers []map[string]string // headers of the requests, the last one is checked
+		want    int
+	}{
+		{"first request", []map[string]string{{}}, http.StatusOK},
+		{"address over the limit", []map[string]string{{}, {}}, http.StatusTooManyRequests},
+		{"user apart from the address", []map[string]string{{}, {"Authorization": "Bearer " + token}}, http.StatusOK},
+		{"api key apart from the address", []map[string]string{{}, {"X-Api-Key": "key"}}, http.StatusOK},
+		{"api key over the limit", []map[string]string{{"X-Api-Key": "key"}, {"X-Api-Key": "key"}}, http.StatusTooManyRequests},
+		{"unknown api key by address", []map[string]string{{}, {"X-Api-Key": "unknown"}}, http.StatusTooManyRequests},
+		{"user over the api key", []map[string]string{
+			{"X-Api-Key": "key"},
+			{"X-Api-Key": "key", "Authorization": "Bearer " + token},
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := &RateLimits{Requests: ratelimit.New(60, 1), ApiKeys: []string{"key"}}
+			router := gin.New()
+			router.GET("/query", AuthMiddleware(verifier), RateLimitMiddleware(limits), func(c *gin.Context) {
+				c.String(http.StatusOK, ClientKey(c.Request.Context()))
+			})
+
+			var rec *httptest.ResponseRecorder
+			for _, headers := range tt.headers {
+				req := httptest.NewRequest(http.MethodGet, "/query", nil)
+				for key, value := range headers {
+					req.Header.Set(key, value)
+				}
+				rec = httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+			}
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if rec.Code != http.StatusTooManyRequests {
+				return
+			}
+
+			retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+			if err != nil || retryAfter < 1 {
+				t.Errorf("Retry-After is %q, want whole seconds", rec.Header().Get("Retry-After"))
+			}
+			resp := struct {
+				Errors []struct {
+					Extensions struct {
+						StatusCode int `json:"status_code"`
+						RetryAfter int `json:"retryAfter"`
+					} `json:"extensions"`
+				} `json:"errors"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Errors) != 1 {
+				t.Fatalf("decode response %s: %v", rec.Body.String(), err)
+			}
+			extensions := resp.Errors[0].Extensions
+			if extensions.StatusCode != http.StatusTooManyRequests || extensions.RetryAfter != retryAfter {
+				t.Errorf("got status_code %d and retryAfter %d, want %d and %d",
+					extensions.StatusCode, extensions.RetryAfter, http.StatusTooManyRequests, retryAfter)
+			}
+		})
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limits := &RateLimits{Requests: ratelimit.New(60, 10), ApiKeys: []string{"key"}}
+	router := gin.New()
+	router.GET("/query", RateLimitMiddleware(limits), func(c *gin.Context) {
+		c.String(http.StatusOK, ClientKey(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name   string
+		apiKey string
+		want   string
+	}{
+		{"address", "", "ip:192.0.2.1"},
+		{"known api key", "key", "key:" + digest("key")},
+		{"unknown api key", "other", "ip:192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/query", nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-Api-Key", tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("got client %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elusiv0/oz_task/pkg/postgres"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+const migrationsTable = "schema_migrations"
+
+// lockId is the key of the advisory lock held while a migration is applied,
+// so migrators started at the same time don't apply it twice.
+const lockId = 7_114_000
+
+var ErrSchemaBehind = errors.New("database schema is behind, run migrate up")
+
+// fileName matches files like 0001_init.up.sql.
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// pool is the part of the pgx pool used by the migrator.
+type pool interface {
+	querier
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+type Migrator struct {
+	pool       pool
+	builder    squirrel.StatementBuilderType
+	logger     *slog.Logger
+	migrations []Migration
+}
+
+func New(
+	db *postgres.Postgres,
+	logger *slog.Logger,
+) (*Migrator, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, fmt.Errorf("Migrator - New: %w", err)
+	}
+
+	return &Migrator{
+		pool:       db.PgxPool,
+		builder:    db.Builder,
+		logger:     logger,
+		migrations: migrations,
+	}, nil
+}
+
+// load reads the embedded migrations ordered by version, every version must have both up and down files.
+func load() ([]Migration, error) {
+	names, err := fs.Glob(files, "sql/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		match := fileName.FindStringSubmatch(path.Base(name))
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", name)
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d must have both up and down files", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every pending migration in its own transaction and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	appliedResp := []Migration{}
+	if err := m.ensureTable(ctx); err != nil {
+		return appliedResp, fmt.Errorf("Migrator - Up: %w", err)
+	}
+
+	for _, migration := range m.migrations {
+		applied, err := m.apply(ctx, migration)
+		if err != nil {
+			return appliedResp, fmt.Errorf("Migrator - Up - %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if applied {
+			appliedResp = append(appliedResp, migration)
+		}
+	}
+
+	return appliedResp, nil
+}
+
+// Down rolls back the last applied migration, nil is returned when nothing was applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, fmt.Errorf("Migrator - Down: %w", err)
+	}
+
+	migration, err := m.rollback(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Migrator - Down: %w", err)
+	}
+
+	return migration, nil
+}
+
+// Status returns every known migration with the time it was applied at.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	statusResp := make([]Status, 0, len(m.migrations))
+	if err := m.ensureTable(ctx); err != nil {
+		return statusResp, fmt.Errorf("Migrator - Status: %w", err)
+	}
+
+	applied, err := m.applied(ctx, m.pool)
+	if err != nil {
+		return statusResp, fmt.Errorf("Migrator - Status: %w", err)
+	}
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statusResp = append(statusResp, status)
+	}
+
+	return statusResp, nil
+}
+
+// Check returns ErrSchemaBehind when some of the migrations are not applied.
+func (m *Migrator) Check(ctx context.Context) error {
+	statusResp, err := m.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("Migrator - Check: %w", err)
+	}
+	pending := 0
+	for _, status := range statusResp {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("Migrator - Check: %w (%d pending)", ErrSchemaBehind, pending)
+	}
+
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) (applied bool, err error) {
+	logger := m.logger.With(slog.Int("version", migration.Version), slog.String("name", migration.Name))
+
+	logger.Debug("initialize transaction...")
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	logger.Debug("transation was initialized successfully")
+	defer func() {
+		if err != nil || !applied {
+			logger.Debug("rollback transaction")
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+		logger.Debug("transaction was committed successfully")
+	}()
+
+	if _, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockId); err != nil {
+		return false, fmt.Errorf("lock: %w", err)
+	}
+	appliedVersions, err := m.applied(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := appliedVersions[migration.Version]; ok {
+		return false, nil
+	}
+
+	logger.Info("applying migration...")
+	if _, err = tx.Exec(ctx, migration.up); err != nil {
+		return false, fmt.Errorf("exec: %w", err)
+	}
+	sql, args, err := m.builder.
+		Insert(migrationsTable).
+		Columns("version", "name").
+		Values(migration.Version, migration.Name).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return false, fmt.Errorf("save version: %w", err)
+	}
+	logger.Info("migration was applied successfully")
+
+	return true, nil
+}
+
+func (m *Migrator) rollback(ctx context.Context) (migration *Migration, err error) {
+	logger := m.logger
+
+	logger.Debug("initialize transaction...")
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	logger.Debug("transation was initialized successfully")
+	defer func() {
+		if err != nil || migration == nil {
+			logger.Debug("rollback transaction")
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+		logger.Debug("transaction was committed successfully")
+	}()
+
+	if _, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockId); err != nil {
+		return nil, fmt.Errorf("lock: %w", err)
+	}
+	appliedVersions, err := m.applied(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for idx := len(m.migrations) - 1; idx >= 0; idx-- {
+		if _, ok := appliedVersions[m.migrations[idx].Version]; ok {
+			migration = &m.migrations[idx]
+			break
+		}
+	}
+	if migration == nil {
+		return nil, nil
+	}
+	logger = logger.With(slog.Int("version", migration.Version), slog.String("name", migration.Name))
+
+	logger.Info("rolling back migration...")
+	if _, err = tx.Exec(ctx, migration.down); err != nil {
+		return nil, fmt.Errorf("%04d_%s - exec: %w", migration.Version, migration.Name, err)
+	}
+	sql, args, err := m.builder.
+		Delete(migrationsTable).
+		Where(squirrel.Eq{"version": migration.Version}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return nil, fmt.Errorf("%04d_%s - delete version: %w", migration.Version, migration.Name, err)
+	}
+	logger.Info("migration was rolled back successfully")
+
+	return migration, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.pool.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+migrationsTable+` (
+    version int PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    applied_at timestamp not null default current_timestamp
+)`)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", migrationsTable, err)
+	}
+
+	return nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (m *Migrator) applied(ctx context.Context, q querier) (map[int]time.Time, error) {
+	appliedResp := make(map[int]time.Time)
+	sql, args, err := m.builder.
+		Select("version", "applied_at").
+		From(migrationsTable).
+		ToSql()
+	if err != nil {
+		return appliedResp, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return appliedResp, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return appliedResp, fmt.Errorf("row scan: %w", err)
+		}
+		appliedResp[version] = appliedAt
+	}
+
+	return appliedResp, rows.Err()
+}
@@ -0,0 +1,257 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// fakePool keeps the versions of schema_migrations, every other statement is recorded.
+// The changes of a transaction are applied on commit only.
+type fakePool struct {
+	versions map[int]time.Time
+	executed []string
+	// failing statement makes the exec fail
+	failing string
+}
+
+type fakeTx struct {
+	pgx.Tx
+	pool     *fakePool
+	versions map[int]time.Time
+	executed []string
+}
+
+type fakeRows struct {
+	pgx.Rows
+	versions []int
+	at       map[int]time.Time
+	idx      int
+}
+
+func newFakePool() *fakePool {
+	return &fakePool{versions: make(map[int]time.Time)}
+}
+
+func (p *fakePool) Begin(ctx context.Context) (pgx.Tx, error) {
+	versions := make(map[int]time.Time, len(p.versions))
+	for version, at := range p.versions {
+		versions[version] = at
+	}
+
+	return &fakeTx{pool: p, versions: versions}, nil
+}
+
+func (p *fakePool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if !strings.HasPrefix(sql, "CREATE TABLE IF NOT EXISTS "+migrationsTable) {
+		p.executed = append(p.executed, sql)
+	}
+
+	return pgconn.CommandTag{}, nil
+}
+
+func (p *fakePool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return query(sql, p.versions)
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	switch {
+	case strings.HasPrefix(sql, "SELECT pg_advisory_xact_lock"):
+	case strings.HasPrefix(sql, "INSERT INTO "+migrationsTable):
+		tx.versions[args[0].(int)] = time.Now()
+	case strings.HasPrefix(sql, "DELETE FROM "+migrationsTable):
+		delete(tx.versions, args[0].(int))
+	case tx.pool.failing != "" && sql == tx.pool.failing:
+		return pgconn.CommandTag{}, errors.New("syntax error")
+	default:
+		tx.executed = append(tx.executed, sql)
+	}
+
+	return pgconn.CommandTag{}, nil
+}
+
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return query(sql, tx.versions)
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.pool.versions = tx.versions
+	tx.pool.executed = append(tx.pool.executed, tx.executed...)
+
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func query(sql string, versions map[int]time.Time) (pgx.Rows, error) {
+	if !strings.HasPrefix(sql, "SELECT version, applied_at FROM "+migrationsTable) {
+		return nil, errors.New("unexpected query " + sql)
+	}
+	rows := &fakeRows{at: versions}
+	for version := range versions {
+		rows.versions = append(rows.versions, version)
+	}
+	sort.Ints(rows.versions)
+
+	return rows, nil
+}
+
+func (r *fakeRows) Next() bool {
+	r.idx++
+	return r.idx <= len(r.versions)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	version := r.versions[r.idx-1]
+	*dest[0].(*int) = version
+	*dest[1].(*time.Time) = r.at[version]
+
+	return nil
+}
+
+func (r *fakeRows) Close() {}
+
+func (r *fakeRows) Err() error {
+	return nil
+}
+
+func newMigrator(t *testing.T, pool *fakePool) *Migrator {
+	migrations, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) < 2 {
+		t.Fatalf("got %d migrations, want at least 2", len(migrations))
+	}
+
+	return &Migrator{
+		pool:       pool,
+		builder:    squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		migrations: migrations,
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx, migration := range migrations {
+		if migration.Version != idx+1 {
+			t.Errorf("got version %d at %d, want versions without gaps", migration.Version, idx)
+		}
+		if migration.up == "" || migration.down == "" {
+			t.Errorf("migration %d has no up or down sql", migration.Version)
+		}
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	pool := newFakePool()
+	m := newMigrator(t, pool)
+	last := m.migrations[len(m.migrations)-1]
+
+	// a new database refuses to start
+	if err := m.Check(ctx); !errors.Is(err, ErrSchemaBehind) {
+		t.Fatalf("check of a new database: got %v, want %v", err, ErrSchemaBehind)
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(m.migrations) || len(pool.executed) != len(m.migrations) {
+		t.Fatalf("got %d applied and %d executed, want %d", len(applied), len(pool.executed), len(m.migrations))
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Fatalf("check after up: %v", err)
+	}
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("second up: got %d applied, %v, want none", len(applied), err)
+	}
+
+	rolledBack, err := m.Down(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack == nil || rolledBack.Version != last.Version || pool.executed[len(pool.executed)-1] != last.down {
+		t.Fatalf("got %+v rolled back, want %d", rolledBack, last.Version)
+	}
+	statusResp, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statusResp {
+		if (status.AppliedAt == nil) != (status.Version == last.Version) {
+			t.Errorf("migration %d: got applied at %v", status.Version, status.AppliedAt)
+		}
+	}
+	if err := m.Check(ctx); !errors.Is(err, ErrSchemaBehind) {
+		t.Fatalf("check after down: got %v, want %v", err, ErrSchemaBehind)
+	}
+
+	applied, err = m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0].Version != last.Version {
+		t.Fatalf("up after down: got %+v, want %d", applied, last.Version)
+	}
+
+	for range m.migrations {
+		if _, err := m.Down(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rolledBack, err := m.Down(ctx); err != nil || rolledBack != nil {
+		t.Fatalf("down of an empty database: got %+v, %v, want nothing", rolledBack, err)
+	}
+	if len(pool.versions) != 0 {
+		t.Errorf("got versions %v after rolling back everything", pool.versions)
+	}
+}
+
+// TestUpFailed fails the last migration, the ones before it stay applied and its version isn't saved.
+func TestUpFailed(t *testing.T) {
+	ctx := context.Background()
+	pool := newFakePool()
+	m := newMigrator(t, pool)
+	last := m.migrations[len(m.migrations)-1]
+	pool.failing = last.up
+
+	applied, err := m.Up(ctx)
+	if err == nil {
+		t.Fatal("got no error of the failing migration")
+	}
+	if len(applied) != len(m.migrations)-1 {
+		t.Errorf("got %d applied, want %d", len(applied), len(m.migrations)-1)
+	}
+	want := make(map[int]bool)
+	got := make(map[int]bool)
+	for _, migration := range m.migrations[:len(m.migrations)-1] {
+		want[migration.Version] = true
+	}
+	for version := range pool.versions {
+		got[version] = true
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got versions %v, want %v", got, want)
+	}
+	if err := m.Check(ctx); !errors.Is(err, ErrSchemaBehind) {
+		t.Errorf("check after a failed up: got %v, want %v", err, ErrSchemaBehind)
+	}
+}
@@ -11,4 +11,4 @@ CREATE TABLE IF NOT EXISTS comments (
     article_id int REFERENCES posts (id),
     parent_id int REFERENCES comments (id),
     created_at timestamp not null default current_timestamp
-);
\ No newline at end of file
+);
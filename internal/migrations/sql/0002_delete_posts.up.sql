@@ -0,0 +1,3 @@
+-- comments are deleted together with their post
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_article_id_fkey;
+ALTER TABLE comments ADD CONSTRAINT comments_article_id_fkey FOREIGN KEY (article_id) REFERENCES posts (id) ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id SERIAL PRIMARY KEY,
+    comment_id int REFERENCES comments (id) ON DELETE CASCADE,
+    _text VARCHAR(2000),
+    created_at timestamp not null default current_timestamp
+);
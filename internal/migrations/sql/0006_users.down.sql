@@ -0,0 +1,4 @@
+ALTER TABLE comments DROP COLUMN IF EXISTS author_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS author_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS closed_by;
+DROP TABLE IF EXISTS users;
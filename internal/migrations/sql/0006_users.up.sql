@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR UNIQUE NOT NULL,
+    created_at timestamp not null default current_timestamp
+);
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS closed_by int REFERENCES users (id);
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS author_id int REFERENCES users (id);
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS author_id int REFERENCES users (id);
@@ -0,0 +1,9 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(_text, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS posts_search_idx ON posts USING GIN (search_vector);
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(_text, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS comments_search_idx ON comments USING GIN (search_vector);
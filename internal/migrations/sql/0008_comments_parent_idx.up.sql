@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS comments_parent_idx ON comments (parent_id);
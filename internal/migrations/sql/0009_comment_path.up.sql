@@ -0,0 +1,8 @@
+-- path holds the ids of the ancestors starting from the root comment
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS path int[] NOT NULL DEFAULT '{}';
+WITH RECURSIVE tree AS (
+    SELECT id, '{}'::int[] AS path FROM comments WHERE parent_id IS NULL
+    UNION ALL
+    SELECT comments.id, tree.path || comments.parent_id FROM comments JOIN tree ON comments.parent_id = tree.id
+)
+UPDATE comments SET path = tree.path FROM tree WHERE comments.id = tree.id;
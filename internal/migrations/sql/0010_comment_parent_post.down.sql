@@ -0,0 +1,2 @@
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_parent_post_fkey;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_id_article_id_key;
@@ -0,0 +1,5 @@
+-- a reply must belong to the same post as its parent
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_parent_post_fkey;
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_id_article_id_key;
+ALTER TABLE comments ADD CONSTRAINT comments_id_article_id_key UNIQUE (id, article_id);
+ALTER TABLE comments ADD CONSTRAINT comments_parent_post_fkey FOREIGN KEY (parent_id, article_id) REFERENCES comments (id, article_id);
@@ -1,10 +1,16 @@
 package converter
 
 import (
+	"time"
+
 	"github.com/elusiv0/oz_task/internal/dto"
 	"github.com/elusiv0/oz_task/internal/repo/model"
 )
 
+const (
+	DeletedCommentText = "[deleted]"
+)
+
 func CommentToRepo(commentDto *dto.Comment) *model.Comment {
 	return &model.Comment{
 		Id:        commentDto.ID,
@@ -20,22 +26,62 @@ func CommentFromRepo(commentModel *model.Comment) *dto.Comment {
 		elem := int(commentModel.ParentId.Int32)
 		pId = &elem
 	}
+	var authorId *int
+	if commentModel.AuthorId.Valid {
+		elem := int(commentModel.AuthorId.Int32)
+		authorId = &elem
+	}
+	text := commentModel.Text
+	if commentModel.DeletedAt.Valid {
+		text = DeletedCommentText
+	}
 	return &dto.Comment{
 		ID:        commentModel.Id,
-		Text:      commentModel.Text,
+		Text:      text,
 		ArticleID: commentModel.ArticleID,
 		ParentID:  pId,
 		CreatedAt: commentModel.CreatedAt,
+		Deleted:   commentModel.DeletedAt.Valid,
+		AuthorID:  authorId,
+		Path:      commentModel.Path,
+		Depth:     len(commentModel.Path),
+	}
+}
+
+func CommentRevisionFromRepo(revisionModel *model.CommentRevision) *dto.CommentRevision {
+	return &dto.CommentRevision{
+		ID:        revisionModel.Id,
+		CommentID: revisionModel.CommentId,
+		Text:      revisionModel.Text,
+		CreatedAt: revisionModel.CreatedAt,
 	}
 }
 
 func PostFromRepo(postModel *model.Post) *dto.Post {
+	var closedAt *time.Time
+	if postModel.ClosedAt.Valid {
+		elem := postModel.ClosedAt.Time
+		closedAt = &elem
+	}
+	var closedBy *int
+	if postModel.ClosedBy.Valid {
+		elem := int(postModel.ClosedBy.Int32)
+		closedBy = &elem
+	}
+	var authorId *int
+	if postModel.AuthorId.Valid {
+		elem := int(postModel.AuthorId.Int32)
+		authorId = &elem
+	}
 	return &dto.Post{
 		ID:        postModel.Id,
 		Text:      postModel.Text,
 		Title:     postModel.Title,
 		Closed:    postModel.Closed,
 		CreatedAt: postModel.CreatedAt,
+		ClosedAt:  closedAt,
+		ClosedBy:  closedBy,
+		AuthorID:  authorId,
 	}
 }
 
@@ -48,3 +94,11 @@ func PostToRepo(postDto *dto.Post) *model.Post {
 		CreatedAt: postDto.CreatedAt,
 	}
 }
+
+func UserFromRepo(userModel *model.User) *dto.User {
+	return &dto.User{
+		ID:        userModel.Id,
+		Username:  userModel.Username,
+		CreatedAt: userModel.CreatedAt,
+	}
+}
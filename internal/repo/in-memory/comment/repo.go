@@ -5,29 +5,35 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
-	"sort"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/elusiv0/oz_task/internal/dto"
 	"github.com/elusiv0/oz_task/internal/repo"
 	"github.com/elusiv0/oz_task/internal/repo/converter"
+	"github.com/elusiv0/oz_task/internal/repo/in-memory/index"
+	"github.com/elusiv0/oz_task/internal/repo/in-memory/order"
 	"github.com/elusiv0/oz_task/internal/repo/model"
 	"github.com/elusiv0/oz_task/internal/util"
 )
 
 type CommentRepository struct {
-	logger *slog.Logger
-	data   map[int]*model.Comment
-	mu     sync.RWMutex
+	logger    *slog.Logger
+	data      map[int]*model.Comment
+	revisions map[int][]*model.CommentRevision
+	index     *index.Index
+	mu        sync.RWMutex
 }
 
 func New(
 	logger *slog.Logger,
 ) *CommentRepository {
 	return &CommentRepository{
-		logger: logger,
-		data:   make(map[int]*model.Comment),
+		logger:    logger,
+		data:      make(map[int]*model.Comment),
+		revisions: make(map[int][]*model.CommentRevision),
+		index:     index.New(),
 	}
 }
 
@@ -35,6 +41,22 @@ var _ repo.CommentRepo = &CommentRepository{}
 
 var idgen *util.Prid = util.NewPrid()
 
+var revisionIdgen *util.Prid = util.NewPrid()
+
+// PostsActivity returns sort keys of the posts accumulated from their visible comments.
+func (c *CommentRepository) PostsActivity() order.Activity {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	activity := order.Activity{}
+	for _, comment := range c.data {
+		if !comment.DeletedAt.Valid {
+			activity.Add(comment.ArticleID, comment.CreatedAt)
+		}
+	}
+
+	return activity
+}
+
 // Get implements repo.CommentRepo.
 func (c *CommentRepository) Get(ctx context.Context, id int) (*dto.Comment, error) {
 	c.mu.RLock()
@@ -48,65 +70,113 @@ func (c *CommentRepository) Get(ctx context.Context, id int) (*dto.Comment, erro
 	return commentResp, nil
 }
 
+// GetByIds implements repo.CommentRepo.
+func (c *CommentRepository) GetByIds(ctx context.Context, ids ...int) ([]*dto.Comment, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	commentsResp := []*dto.Comment{}
+	for _, id := range ids {
+		if commentModel, ok := c.data[id]; ok {
+			commentsResp = append(commentsResp, converter.CommentFromRepo(commentModel))
+		}
+	}
+
+	return commentsResp, nil
+}
+
 // GetMany implements repo.CommentRepo.
 func (c *CommentRepository) GetMany(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]*dto.Comment, error) {
+	if !dto.SameBatch(commentsReq...) {
+		return []*dto.Comment{}, fmt.Errorf("CommentRepository - GetMany: requests of a batch must share the filter, the page and the order")
+	}
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	commentssl := make(map[int][]*model.Comment)
 	set := make(map[int]bool)
+	byAuthor := false
 	byParent := false
 	byPost := false
-	after := commentsReq[0].After
-	first := commentsReq[0].First
-	if commentsReq[0].ParentId != nil {
+	page := commentsReq[0].Page
+	ord := commentsReq[0].Order
+	if commentsReq[0].AuthorId != nil {
+		byAuthor = true
+	} else if commentsReq[0].ParentId != nil {
 		byParent = true
 	} else if commentsReq[0].PostId != nil {
 		byPost = true
 	}
-	if !byPost && !byParent {
-		return []*dto.Comment{}, fmt.Errorf("CommentRepository - GetMany: required postId or parentId")
+	if !byPost && !byParent && !byAuthor {
+		return []*dto.Comment{}, fmt.Errorf("CommentRepository - GetMany: required postId, parentId or authorId")
 	}
 	for _, val := range commentsReq {
-		if val.ParentId != nil {
+		if val.AuthorId != nil {
+			set[*val.AuthorId] = true
+		} else if val.ParentId != nil {
 			set[*val.ParentId] = true
 		} else {
 			set[*val.PostId] = true
 		}
 	}
 
+	hasReplies := make(map[int]bool)
+	activity := order.Activity{}
+	for _, comment := range c.data {
+		if comment.ParentId.Valid {
+			hasReplies[int(comment.ParentId.Int32)] = true
+			if !comment.DeletedAt.Valid {
+				activity.Add(int(comment.ParentId.Int32), comment.CreatedAt)
+			}
+		}
+	}
+	keyOf := func(comment *model.Comment) order.Key {
+		return activity.Key(comment.Id, comment.CreatedAt)
+	}
+	inPage := func(comment *model.Comment) bool {
+		return order.InPage(ord, page, keyOf(comment))
+	}
+
 	for _, post := range c.data {
+		if byAuthor {
+			if !post.AuthorId.Valid || post.DeletedAt.Valid {
+				continue
+			}
+			authorId := int(post.AuthorId.Int32)
+			if _, ok := set[authorId]; ok {
+				if inPage(post) {
+					commentssl[authorId] = append(commentssl[authorId], post)
+				}
+			}
+			continue
+		}
+		if post.DeletedAt.Valid && !hasReplies[post.Id] {
+			continue
+		}
 		if byParent {
 			if !post.ParentId.Valid {
 				continue
 			}
 			parentId := int(post.ParentId.Int32)
 			if _, ok := set[parentId]; ok {
-				if after == nil || post.Id < *after {
+				if inPage(post) {
 					commentssl[parentId] = append(commentssl[parentId], post)
 				}
 			}
 		} else {
 			postId := post.ArticleID
 			if _, ok := set[postId]; ok && !post.ParentId.Valid {
-				if after == nil || post.Id < *after {
+				if inPage(post) {
 					commentssl[postId] = append(commentssl[postId], post)
 				}
 			}
 		}
 	}
-	for _, val := range commentssl {
-		sort.Slice(val, func(i, j int) bool {
-			return val[i].Id > val[j].Id
-		})
-	}
 	var commentsResp []*dto.Comment
 
 	for _, comm := range commentssl {
-		if len(comm) > first {
-			comm = comm[:first+1]
-		}
+		comm = order.Window(ord, page, comm, keyOf)
 		for _, commBy := range comm {
 			currCommentDto := converter.CommentFromRepo(commBy)
+			currCommentDto.Cursor = keyOf(commBy).Cursor(ord)
 			commentsResp = append(commentsResp, currCommentDto)
 		}
 	}
@@ -124,18 +194,235 @@ func (c *CommentRepository) Insert(ctx context.Context, newComment dto.NewCommen
 	pId := sql.NullInt32{
 		Valid: false,
 	}
+	path := []int{}
 	if newComment.ParentID != nil {
 		pId.Int32 = int32(*newComment.ParentID)
 		pId.Valid = true
+		if parent, ok := c.data[*newComment.ParentID]; ok {
+			path = append(slices.Clone(parent.Path), parent.Id)
+		}
+	}
+	authorId := sql.NullInt32{
+		Valid: false,
+	}
+	if newComment.AuthorID != nil {
+		authorId.Int32 = int32(*newComment.AuthorID)
+		authorId.Valid = true
 	}
 	commentModel := &model.Comment{
 		Id:        idgen.GenerateId(),
 		Text:      newComment.Text,
 		ArticleID: newComment.ArticleID,
 		ParentId:  pId,
+		AuthorId:  authorId,
+		Path:      path,
 		CreatedAt: time.Now(),
 	}
 	c.data[commentModel.Id] = commentModel
+	c.indexComment(commentModel)
+	commentResp := converter.CommentFromRepo(commentModel)
+	return commentResp, nil
+}
+
+// DeleteByPost deletes the comments of the post, it is called by the post repo in place of ON DELETE CASCADE.
+func (c *CommentRepository) DeleteByPost(ctx context.Context, postId int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, comment := range c.data {
+		if comment.ArticleID == postId {
+			delete(c.data, id)
+			delete(c.revisions, id)
+			c.index.Remove(id)
+		}
+	}
+
+	return nil
+}
+
+// Edit implements repo.CommentRepo.
+func (c *CommentRepository) Edit(ctx context.Context, id int, text string) (*dto.Comment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	commentModel, ok := c.data[id]
+	if !ok {
+		return &dto.Comment{}, dto.NewCustomError(repo.CommentNotFoundErr, map[string]int{"id": id})
+	}
+	if commentModel.DeletedAt.Valid {
+		return &dto.Comment{}, dto.NewCustomError(repo.CommentDeletedErr, map[string]int{"id": id})
+	}
+	revisionModel := &model.CommentRevision{
+		Id:        revisionIdgen.GenerateId(),
+		CommentId: id,
+		Text:      commentModel.Text,
+		CreatedAt: time.Now(),
+	}
+	c.revisions[id] = append(c.revisions[id], revisionModel)
+	commentModel.Text = text
+	c.indexComment(commentModel)
 	commentResp := converter.CommentFromRepo(commentModel)
+
 	return commentResp, nil
 }
+
+// GetRevisions implements repo.CommentRepo.
+func (c *CommentRepository) GetRevisions(ctx context.Context, commentId int) ([]*dto.CommentRevision, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	revisionsResp := []*dto.CommentRevision{}
+	revisions := c.revisions[commentId]
+	for idx := len(revisions) - 1; idx >= 0; idx-- {
+		revisionsResp = append(revisionsResp, converter.CommentRevisionFromRepo(revisions[idx]))
+	}
+
+	return revisionsResp, nil
+}
+
+// Delete implements repo.CommentRepo.
+// The comment is tombstoned instead of being removed, so its replies keep a valid parent.
+func (c *CommentRepository) Delete(ctx context.Context, id int) (*dto.Comment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	commentModel, ok := c.data[id]
+	if !ok {
+		return &dto.Comment{}, dto.NewCustomError(repo.CommentNotFoundErr, map[string]int{"id": id})
+	}
+	if !commentModel.DeletedAt.Valid {
+		commentModel.DeletedAt = sql.NullTime{
+			Time:  time.Now(),
+			Valid: true,
+		}
+	}
+	c.index.Remove(id)
+	commentResp := converter.CommentFromRepo(commentModel)
+
+	return commentResp, nil
+}
+
+// Count implements repo.CommentRepo.
+// Every request is counted the same way as its comments are selected by GetMany, regardless of the page.
+func (c *CommentRepository) Count(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	hasReplies := make(map[int]bool)
+	for _, comment := range c.data {
+		if comment.ParentId.Valid {
+			hasReplies[int(comment.ParentId.Int32)] = true
+		}
+	}
+	byPost := make(map[int]int)
+	byParent := make(map[int]int)
+	byAuthor := make(map[int]int)
+	for _, comment := range c.data {
+		if comment.AuthorId.Valid && !comment.DeletedAt.Valid {
+			byAuthor[int(comment.AuthorId.Int32)]++
+		}
+		if comment.DeletedAt.Valid && !hasReplies[comment.Id] {
+			continue
+		}
+		if comment.ParentId.Valid {
+			byParent[int(comment.ParentId.Int32)]++
+		} else {
+			byPost[comment.ArticleID]++
+		}
+	}
+
+	countsResp := make([]int, len(commentsReq))
+	for idx, val := range commentsReq {
+		if val.AuthorId != nil {
+			countsResp[idx] = byAuthor[*val.AuthorId]
+		} else if val.ParentId != nil {
+			countsResp[idx] = byParent[*val.ParentId]
+		} else if val.PostId != nil {
+			countsResp[idx] = byPost[*val.PostId]
+		}
+	}
+
+	return countsResp, nil
+}
+
+// Search implements repo.CommentRepo.
+// Deleted comments are not searchable.
+func (c *CommentRepository) Search(ctx context.Context, query string, limit int) ([]*dto.SearchResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	resultsResp := []*dto.SearchResult{}
+	for _, hit := range c.index.Top(query, limit) {
+		resultsResp = append(resultsResp, &dto.SearchResult{
+			Score:   hit.Score,
+			Comment: converter.CommentFromRepo(c.data[hit.Id]),
+		})
+	}
+
+	return resultsResp, nil
+}
+
+// Thread implements repo.CommentRepo.
+// The subtree is walked depth-first, replies follow their parent from the oldest one.
+// One extra node is returned to detect truncation.
+func (c *CommentRepository) Thread(ctx context.Context, threadReq dto.ThreadRequest) ([]*dto.ThreadNode, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	hasReplies := make(map[int]bool)
+	for _, comment := range c.data {
+		if comment.ParentId.Valid {
+			hasReplies[int(comment.ParentId.Int32)] = true
+		}
+	}
+	var roots []*model.Comment
+	replies := make(map[int][]*model.Comment)
+	for _, comment := range c.data {
+		if comment.DeletedAt.Valid && !hasReplies[comment.Id] {
+			continue
+		}
+		if comment.ParentId.Valid {
+			replies[int(comment.ParentId.Int32)] = append(replies[int(comment.ParentId.Int32)], comment)
+		}
+		if threadReq.CommentId != nil && comment.Id == *threadReq.CommentId ||
+			threadReq.PostId != nil && comment.ArticleID == *threadReq.PostId && !comment.ParentId.Valid {
+			roots = append(roots, comment)
+		}
+	}
+	byId := func(a, b *model.Comment) int {
+		return a.Id - b.Id
+	}
+
+	type frame struct {
+		comment *model.Comment
+		depth   int
+	}
+	nodesResp := []*dto.ThreadNode{}
+	stack := make([]frame, 0, len(roots))
+	slices.SortFunc(roots, byId)
+	for idx := len(roots) - 1; idx >= 0; idx-- {
+		stack = append(stack, frame{comment: roots[idx]})
+	}
+	for len(stack) > 0 && len(nodesResp) <= threadReq.MaxNodes {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodesResp = append(nodesResp, &dto.ThreadNode{
+			Depth:   cur.depth,
+			Comment: converter.CommentFromRepo(cur.comment),
+		})
+		if cur.depth >= threadReq.MaxDepth {
+			continue
+		}
+		children := replies[cur.comment.Id]
+		slices.SortFunc(children, byId)
+		for idx := len(children) - 1; idx >= 0; idx-- {
+			stack = append(stack, frame{comment: children[idx], depth: cur.depth + 1})
+		}
+	}
+
+	if len(nodesResp) == 0 {
+		return nodesResp, dto.NewCustomError(repo.CommentsNotFoundErr, threadReq)
+	}
+
+	return nodesResp, nil
+}
+
+func (c *CommentRepository) indexComment(commentModel *model.Comment) {
+	c.index.Add(
+		commentModel.Id,
+		index.Field{Text: commentModel.Text, Weight: index.WeightB},
+	)
+}
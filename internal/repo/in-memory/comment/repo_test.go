@@ -0,0 +1,56 @@
+package comment
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+// TestCount counts a batch of requests by post, parent and author in one call,
+// tombstones are counted in their thread while they have replies and never for their author.
+func TestCount(t *testing.T) {
+	ctx := context.Background()
+	commentRepo := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	postId, other := 2001, 2002
+	first, second := 1001, 1002
+
+	insert := func(parentId *int, authorId int) int {
+		comment, err := commentRepo.Insert(ctx, dto.NewComment{Text: "text", ArticleID: postId, ParentID: parentId, AuthorID: &authorId})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return comment.ID
+	}
+	remove := func(id int) {
+		if _, err := commentRepo.Delete(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := insert(nil, first)
+	remove(insert(nil, second))
+	insert(&root, first)
+	remove(insert(&root, second))
+	tombstone := insert(nil, first)
+	insert(&tombstone, second)
+	remove(tombstone)
+
+	commentsReq := []dto.GetCommentsRequest{
+		{PostId: &postId},
+		{ParentId: &root},
+		{ParentId: &tombstone},
+		{AuthorId: &first},
+		{AuthorId: &second},
+		{PostId: &other},
+	}
+	countsResp, err := commentRepo.Count(ctx, commentsReq...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 1, 1, 2, 1, 0}; !reflect.DeepEqual(countsResp, want) {
+		t.Errorf("got %v, want %v", countsResp, want)
+	}
+}
@@ -0,0 +1,119 @@
+package index
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Weights of the fields, the same as the default weights of postgres ts_rank.
+const (
+	WeightA = 1.0
+	WeightB = 0.4
+)
+
+// Hit is a document matched by the search query.
+type Hit struct {
+	Id    int
+	Score float64
+}
+
+// Field is a part of the indexed document, terms of the field are scored with its weight.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Index is an inverted index of documents by their terms,
+// it is not safe for concurrent use and is guarded by the owning repo.
+type Index struct {
+	terms map[string]map[int]float64
+	docs  map[int][]string
+}
+
+func New() *Index {
+	return &Index{
+		terms: make(map[string]map[int]float64),
+		docs:  make(map[int][]string),
+	}
+}
+
+// Tokenize splits text into lower-cased terms of letters and digits,
+// the same way the postgres 'simple' text search configuration does.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Add indexes the document, a previously indexed document with the same id is replaced.
+func (i *Index) Add(id int, fields ...Field) {
+	i.Remove(id)
+	for _, field := range fields {
+		for _, term := range Tokenize(field.Text) {
+			if i.terms[term] == nil {
+				i.terms[term] = make(map[int]float64)
+			}
+			if _, ok := i.terms[term][id]; !ok {
+				i.docs[id] = append(i.docs[id], term)
+			}
+			i.terms[term][id] += field.Weight
+		}
+	}
+}
+
+func (i *Index) Remove(id int) {
+	for _, term := range i.docs[id] {
+		delete(i.terms[term], id)
+		if len(i.terms[term]) == 0 {
+			delete(i.terms, term)
+		}
+	}
+	delete(i.docs, id)
+}
+
+// Search returns scores of documents containing every term of the query.
+func (i *Index) Search(query string) map[int]float64 {
+	scores := make(map[int]float64)
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return scores
+	}
+
+	for id, score := range i.terms[terms[0]] {
+		scores[id] = score
+	}
+	for _, term := range terms[1:] {
+		postings := i.terms[term]
+		for id := range scores {
+			score, ok := postings[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += score
+		}
+	}
+
+	return scores
+}
+
+// Top returns at most limit hits of the query ordered by score, newer documents first on ties.
+func (i *Index) Top(query string, limit int) []Hit {
+	scores := i.Search(query)
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{Id: id, Score: score})
+	}
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].Id > hits[b].Id
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits
+}
@@ -0,0 +1,131 @@
+package order
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+// Key holds the values the rows are sorted by.
+type Key struct {
+	Id       int
+	Replies  int
+	Activity time.Time
+}
+
+// Less reports whether the row with key a goes before the row with key b,
+// it mirrors the ordering of the postgres repos.
+func Less(order dto.Order, a, b Key) bool {
+	switch order {
+	case dto.OrderOldest:
+		return a.Id < b.Id
+	case dto.OrderMostReplies:
+		if a.Replies != b.Replies {
+			return a.Replies > b.Replies
+		}
+	case dto.OrderRecentActivity:
+		if !a.Activity.Equal(b.Activity) {
+			return a.Activity.After(b.Activity)
+		}
+	}
+
+	return a.Id > b.Id
+}
+
+// FromCursor returns the key of the row the cursor points to.
+func FromCursor(cursor dto.Cursor) Key {
+	key := Key{Id: cursor.Id}
+	if cursor.Replies != nil {
+		key.Replies = *cursor.Replies
+	}
+	if cursor.Activity != nil {
+		key.Activity = *cursor.Activity
+	}
+
+	return key
+}
+
+// Cursor returns the cursor of the row in the order.
+func (k Key) Cursor(order dto.Order) *dto.Cursor {
+	cursor := &dto.Cursor{
+		Order: order,
+		Id:    k.Id,
+	}
+	switch order {
+	case dto.OrderMostReplies:
+		replies := k.Replies
+		cursor.Replies = &replies
+	case dto.OrderRecentActivity:
+		activity := k.Activity
+		cursor.Activity = &activity
+	}
+
+	return cursor
+}
+
+// InPage reports whether the row with the key is placed between the cursors of the page.
+func InPage(order dto.Order, page dto.Page, key Key) bool {
+	if page.After != nil && !Less(order, FromCursor(*page.After), key) {
+		return false
+	}
+	if page.Before != nil && !Less(order, key, FromCursor(*page.Before)) {
+		return false
+	}
+
+	return true
+}
+
+// Window sorts the rows and cuts the page from them together with one extra row,
+// which tells that there is one more page. Backward pages are cut from the end.
+func Window[T any](order dto.Order, page dto.Page, rows []T, keyOf func(T) Key) []T {
+	backward := page.Backward()
+	sort.Slice(rows, func(i, j int) bool {
+		if backward {
+			return Less(order, keyOf(rows[j]), keyOf(rows[i]))
+		}
+		return Less(order, keyOf(rows[i]), keyOf(rows[j]))
+	})
+	limit := page.First
+	if backward {
+		limit = page.Last
+	}
+	if len(rows) > limit+1 {
+		rows = rows[:limit+1]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	return rows
+}
+
+// Activity accumulates sort keys of the rows from their visible children.
+type Activity map[int]*Key
+
+// Add counts the child created at the given time for the row with the id.
+func (a Activity) Add(id int, createdAt time.Time) {
+	key, ok := a[id]
+	if !ok {
+		key = &Key{Id: id}
+		a[id] = key
+	}
+	key.Replies++
+	if createdAt.After(key.Activity) {
+		key.Activity = createdAt
+	}
+}
+
+// Key returns the sort key of the row, which activity starts at its creation.
+func (a Activity) Key(id int, createdAt time.Time) Key {
+	key := Key{Id: id, Activity: createdAt}
+	if child, ok := a[id]; ok {
+		key.Replies = child.Replies
+		if child.Activity.After(key.Activity) {
+			key.Activity = child.Activity
+		}
+	}
+
+	return key
+}
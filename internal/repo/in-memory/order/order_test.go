@@ -0,0 +1,139 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// keys have ties on the replies and on the activity, so the id has to break them
+var keys = []Key{
+	{Id: 1, Replies: 2, Activity: start.Add(3 * time.Minute)},
+	{Id: 2, Replies: 0, Activity: start.Add(1 * time.Minute)},
+	{Id: 3, Replies: 2, Activity: start.Add(5 * time.Minute)},
+	{Id: 4, Replies: 5, Activity: start.Add(3 * time.Minute)},
+	{Id: 5, Replies: 0, Activity: start.Add(2 * time.Minute)},
+}
+
+var orders = []struct {
+	order dto.Order
+	want  []int
+}{
+	{dto.OrderNewest, []int{5, 4, 3, 2, 1}},
+	{dto.OrderOldest, []int{1, 2, 3, 4, 5}},
+	{dto.OrderMostReplies, []int{4, 3, 1, 5, 2}},
+	{dto.OrderRecentActivity, []int{3, 4, 1, 5, 2}},
+}
+
+func TestForwardPages(t *testing.T) {
+	for _, tt := range orders {
+		for _, size := range []int{1, 2, 3, 5} {
+			t.Run(string(tt.order), func(t *testing.T) {
+				got := []int{}
+				page := dto.Page{First: size}
+				for range len(keys) + 1 {
+					rows := cut(tt.order, page)
+					hasNext := len(rows) > size
+					if hasNext {
+						rows = rows[:size]
+					}
+					for _, row := range rows {
+						got = append(got, row.Id)
+					}
+					if !hasNext {
+						break
+					}
+					page.After = rows[len(rows)-1].Cursor(tt.order)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("pages of %d: got %v, want %v", size, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestBackwardPages(t *testing.T) {
+	for _, tt := range orders {
+		for _, size := range []int{1, 2, 3, 5} {
+			t.Run(string(tt.order), func(t *testing.T) {
+				got := []int{}
+				page := dto.Page{Last: size}
+				for range len(keys) + 1 {
+					rows := cut(tt.order, page)
+					// the extra row is the first one, the page keeps the order of the display
+					hasPrev := len(rows) > size
+					if hasPrev {
+						rows = rows[1:]
+					}
+					ids := []int{}
+					for _, row := range rows {
+						ids = append(ids, row.Id)
+					}
+					got = append(ids, got...)
+					if !hasPrev {
+						break
+					}
+					page.Before = rows[0].Cursor(tt.order)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("pages of %d: got %v, want %v", size, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCursorKey(t *testing.T) {
+	for _, tt := range orders {
+		t.Run(string(tt.order), func(t *testing.T) {
+			for _, key := range keys {
+				got := FromCursor(*key.Cursor(tt.order))
+				// the cursor holds only the sort key of its order
+				if Less(tt.order, got, key) || Less(tt.order, key, got) {
+					t.Errorf("key %+v comes back from the cursor as %+v", key, got)
+				}
+			}
+		})
+	}
+}
+
+func TestActivity(t *testing.T) {
+	activity := Activity{}
+	activity.Add(1, start.Add(time.Minute))
+	activity.Add(1, start.Add(-time.Minute))
+
+	tests := []struct {
+		name      string
+		id        int
+		createdAt time.Time
+		want      Key
+	}{
+		{"replies", 1, start, Key{Id: 1, Replies: 2, Activity: start.Add(time.Minute)}},
+		{"no replies", 2, start, Key{Id: 2, Activity: start}},
+		{"replies older than the row", 1, start.Add(time.Hour), Key{Id: 1, Replies: 2, Activity: start.Add(time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := activity.Key(tt.id, tt.createdAt); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// cut selects the page the same way as the repos: the rows between the cursors, then the window.
+func cut(order dto.Order, page dto.Page) []Key {
+	rows := []Key{}
+	for _, key := range keys {
+		if InPage(order, page, key) {
+			rows = append(rows, key)
+		}
+	}
+
+	return Window(order, page, rows, func(key Key) Key { return key })
+}
@@ -2,30 +2,39 @@ package post
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"log/slog"
-	"sort"
 	"sync"
 	"time"
 
 	"github.com/elusiv0/oz_task/internal/dto"
 	"github.com/elusiv0/oz_task/internal/repo"
 	"github.com/elusiv0/oz_task/internal/repo/converter"
+	"github.com/elusiv0/oz_task/internal/repo/in-memory/comment"
+	"github.com/elusiv0/oz_task/internal/repo/in-memory/index"
+	"github.com/elusiv0/oz_task/internal/repo/in-memory/order"
 	"github.com/elusiv0/oz_task/internal/repo/model"
 	"github.com/elusiv0/oz_task/internal/util"
 )
 
 type PostRepository struct {
-	logger *slog.Logger
-	data   map[int]*model.Post
-	mu     sync.RWMutex
+	commentRepo *comment.CommentRepository
+	logger      *slog.Logger
+	data        map[int]*model.Post
+	index       *index.Index
+	mu          sync.RWMutex
 }
 
 func New(
+	commentRepo *comment.CommentRepository,
 	logger *slog.Logger,
 ) *PostRepository {
 	return &PostRepository{
-		logger: logger,
-		data:   make(map[int]*model.Post),
+		commentRepo: commentRepo,
+		logger:      logger,
+		data:        make(map[int]*model.Post),
+		index:       index.New(),
 	}
 }
 
@@ -47,31 +56,54 @@ func (p *PostRepository) Get(ctx context.Context, id int) (*dto.Post, error) {
 }
 
 // GetMany implements repo.PostRepo.
-func (p *PostRepository) GetMany(ctx context.Context, postsReq dto.GetPostsRequest) ([]*dto.Post, error) {
+func (p *PostRepository) GetMany(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]*dto.Post, error) {
+	if !dto.SameBatch(postsReq...) || (len(postsReq) > 1 && postsReq[0].AuthorId == nil) {
+		return []*dto.Post{}, fmt.Errorf("PostRepository - GetMany: requests of a batch must be by author and share the page and the order")
+	}
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	var posts []*model.Post
+	page, ord := postsReq[0].Page, postsReq[0].Order
+	byAuthor := postsReq[0].AuthorId != nil
+	authors := make(map[int]bool)
+	for _, val := range postsReq {
+		if val.AuthorId != nil {
+			authors[*val.AuthorId] = true
+		}
+	}
+	postsByAuthor := make(map[int][]*model.Post)
 
+	activity := p.commentRepo.PostsActivity()
+	keys := make(map[int]order.Key, len(p.data))
+	for _, post := range p.data {
+		keys[post.Id] = activity.Key(post.Id, post.CreatedAt)
+	}
 	for _, post := range p.data {
-		if postsReq.After == nil || post.Id < *postsReq.After {
-			posts = append(posts, post)
+		authorId := 0
+		if byAuthor {
+			if !post.AuthorId.Valid || !authors[int(post.AuthorId.Int32)] {
+				continue
+			}
+			authorId = int(post.AuthorId.Int32)
+		}
+		if order.InPage(ord, page, keys[post.Id]) {
+			postsByAuthor[authorId] = append(postsByAuthor[authorId], post)
 		}
 	}
 
-	sort.Slice(posts, func(i, j int) bool {
-		return posts[i].Id > posts[j].Id
-	})
-	if len(posts) > postsReq.First {
-		posts = posts[:postsReq.First+1]
-	}
 	var postsDto []*dto.Post
-	for _, post := range posts {
-		curPostDto := converter.PostFromRepo(post)
-		postsDto = append(postsDto, curPostDto)
+	for _, posts := range postsByAuthor {
+		posts = order.Window(ord, page, posts, func(post *model.Post) order.Key {
+			return keys[post.Id]
+		})
+		for _, post := range posts {
+			curPostDto := converter.PostFromRepo(post)
+			curPostDto.Cursor = keys[post.Id].Cursor(ord)
+			postsDto = append(postsDto, curPostDto)
+		}
 	}
 
-	if len(postsDto) == 0 {
-		return postsDto, dto.NewCustomError(repo.PostsNotFoundErr, postsReq)
+	if len(postsDto) == 0 && len(postsReq) == 1 {
+		return postsDto, dto.NewCustomError(repo.PostsNotFoundErr, postsReq[0])
 	}
 
 	return postsDto, nil
@@ -88,8 +120,145 @@ func (p *PostRepository) Insert(ctx context.Context, newPost dto.NewPost) (*dto.
 		Closed:    newPost.Closed,
 		CreatedAt: time.Now(),
 	}
+	if newPost.AuthorID != nil {
+		postModel.AuthorId = sql.NullInt32{
+			Int32: int32(*newPost.AuthorID),
+			Valid: true,
+		}
+	}
+	if newPost.Closed {
+		postModel.ClosedAt = sql.NullTime{
+			Time:  postModel.CreatedAt,
+			Valid: true,
+		}
+	}
 	p.data[postModel.Id] = postModel
+	p.indexPost(postModel)
+	postResp := converter.PostFromRepo(postModel)
+
+	return postResp, nil
+}
+
+// Update implements repo.PostRepo.
+func (p *PostRepository) Update(ctx context.Context, id int, updatePost dto.UpdatePost) (*dto.Post, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	postModel, ok := p.data[id]
+	if !ok {
+		return &dto.Post{}, dto.NewCustomError(repo.PostNotFoundErr, id)
+	}
+	if updatePost.Title != nil {
+		postModel.Title = *updatePost.Title
+	}
+	if updatePost.Text != nil {
+		postModel.Text = *updatePost.Text
+	}
+	p.indexPost(postModel)
+	postResp := converter.PostFromRepo(postModel)
+
+	return postResp, nil
+}
+
+// Delete implements repo.PostRepo.
+// Comments of the post are deleted together with it.
+func (p *PostRepository) Delete(ctx context.Context, id int) (*dto.Post, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	postModel, ok := p.data[id]
+	if !ok {
+		return &dto.Post{}, dto.NewCustomError(repo.PostNotFoundErr, id)
+	}
+	if err := p.commentRepo.DeleteByPost(ctx, id); err != nil {
+		return &dto.Post{}, fmt.Errorf("PostRepository - Delete: %w", err)
+	}
+	delete(p.data, id)
+	p.index.Remove(id)
+	postResp := converter.PostFromRepo(postModel)
+
+	return postResp, nil
+}
+
+// Close implements repo.PostRepo.
+func (p *PostRepository) Close(ctx context.Context, id int, closedBy int) (*dto.Post, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	postModel, ok := p.data[id]
+	if !ok {
+		return &dto.Post{}, dto.NewCustomError(repo.PostNotFoundErr, id)
+	}
+	postModel.Closed = true
+	postModel.ClosedAt = sql.NullTime{
+		Time:  time.Now(),
+		Valid: true,
+	}
+	postModel.ClosedBy = sql.NullInt32{
+		Int32: int32(closedBy),
+		Valid: true,
+	}
+	postResp := converter.PostFromRepo(postModel)
+
+	return postResp, nil
+}
+
+// Reopen implements repo.PostRepo.
+func (p *PostRepository) Reopen(ctx context.Context, id int) (*dto.Post, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	postModel, ok := p.data[id]
+	if !ok {
+		return &dto.Post{}, dto.NewCustomError(repo.PostNotFoundErr, id)
+	}
+	postModel.Closed = false
+	postModel.ClosedAt = sql.NullTime{}
+	postModel.ClosedBy = sql.NullInt32{}
 	postResp := converter.PostFromRepo(postModel)
 
 	return postResp, nil
 }
+
+// Count implements repo.PostRepo.
+// Requests without an author count all of the posts.
+func (p *PostRepository) Count(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]int, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	byAuthor := make(map[int]int)
+	for _, post := range p.data {
+		if post.AuthorId.Valid {
+			byAuthor[int(post.AuthorId.Int32)]++
+		}
+	}
+
+	countsResp := make([]int, len(postsReq))
+	for idx, val := range postsReq {
+		if val.AuthorId != nil {
+			countsResp[idx] = byAuthor[*val.AuthorId]
+		} else {
+			countsResp[idx] = len(p.data)
+		}
+	}
+
+	return countsResp, nil
+}
+
+// Search implements repo.PostRepo.
+func (p *PostRepository) Search(ctx context.Context, query string, limit int) ([]*dto.SearchResult, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	resultsResp := []*dto.SearchResult{}
+	for _, hit := range p.index.Top(query, limit) {
+		resultsResp = append(resultsResp, &dto.SearchResult{
+			Score: hit.Score,
+			Post:  converter.PostFromRepo(p.data[hit.Id]),
+		})
+	}
+
+	return resultsResp, nil
+}
+
+func (p *PostRepository) indexPost(postModel *model.Post) {
+	p.index.Add(
+		postModel.Id,
+		index.Field{Text: postModel.Title, Weight: index.WeightA},
+		index.Field{Text: postModel.Text, Weight: index.WeightB},
+	)
+}
@@ -0,0 +1,91 @@
+package post
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+	imCommentRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/comment"
+)
+
+// TestGetManyByAuthor pages the posts of two authors in one batch, the repo returns
+// the extra row of every author so each of them knows whether there is one more page.
+func TestGetManyByAuthor(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	postRepo := New(imCommentRepo.New(logger), logger)
+
+	first, second := 1001, 1002
+	ids := make(map[int][]int)
+	for range 3 {
+		for _, author := range []int{first, second} {
+			post, err := postRepo.Insert(ctx, dto.NewPost{Title: "title", Text: "text", AuthorID: &author})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids[author] = append(ids[author], post.ID)
+		}
+	}
+
+	tests := []struct {
+		name string
+		page func(author int) dto.Page
+		want func(author int) []int
+	}{
+		{
+			name: "first page",
+			page: func(int) dto.Page { return dto.Page{First: 2} },
+			want: func(author int) []int { return []int{ids[author][2], ids[author][1], ids[author][0]} },
+		},
+		{
+			name: "after",
+			page: func(author int) dto.Page {
+				return dto.Page{First: 2, After: &dto.Cursor{Order: dto.OrderNewest, Id: ids[author][2]}}
+			},
+			want: func(author int) []int { return []int{ids[author][1], ids[author][0]} },
+		},
+		{
+			name: "last page",
+			page: func(int) dto.Page { return dto.Page{Last: 2} },
+			want: func(author int) []int { return []int{ids[author][2], ids[author][1], ids[author][0]} },
+		},
+		{
+			name: "before",
+			page: func(author int) dto.Page {
+				return dto.Page{Last: 2, Before: &dto.Cursor{Order: dto.OrderNewest, Id: ids[author][0]}}
+			},
+			want: func(author int) []int { return []int{ids[author][2], ids[author][1]} },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a batch shares its page, so every author is fetched alone once a cursor is given
+			batches := [][]int{{first, second}}
+			if p := tt.page(first); p.After != nil || p.Before != nil {
+				batches = [][]int{{first}, {second}}
+			}
+			for _, batch := range batches {
+				postsReq := []dto.GetPostsRequest{}
+				for _, author := range batch {
+					postsReq = append(postsReq, dto.GetPostsRequest{Page: tt.page(author), AuthorId: &author, Order: dto.OrderNewest})
+				}
+				postsResp, err := postRepo.GetMany(ctx, postsReq...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := make(map[int][]int)
+				for _, post := range postsResp {
+					got[*post.AuthorID] = append(got[*post.AuthorID], post.ID)
+				}
+				for _, author := range batch {
+					if !reflect.DeepEqual(got[author], tt.want(author)) {
+						t.Errorf("author %d: got %v, want %v", author, got[author], tt.want(author))
+					}
+				}
+			}
+		})
+	}
+}
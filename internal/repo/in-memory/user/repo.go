@@ -0,0 +1,111 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/repo"
+	"github.com/elusiv0/oz_task/internal/repo/converter"
+	"github.com/elusiv0/oz_task/internal/repo/model"
+	"github.com/elusiv0/oz_task/internal/util"
+)
+
+type UserRepository struct {
+	logger *slog.Logger
+	data   map[int]*model.User
+	mu     sync.RWMutex
+}
+
+func New(
+	logger *slog.Logger,
+) *UserRepository {
+	return &UserRepository{
+		logger: logger,
+		data:   make(map[int]*model.User),
+	}
+}
+
+var _ repo.UserRepo = &UserRepository{}
+
+var idgen *util.Prid = util.NewPrid()
+
+// Get implements repo.UserRepo.
+func (u *UserRepository) Get(ctx context.Context, id int) (*dto.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	userModel, ok := u.data[id]
+	if !ok {
+		return &dto.User{}, dto.NewCustomError(repo.UserNotFoundErr, id)
+	}
+	userResp := converter.UserFromRepo(userModel)
+
+	return userResp, nil
+}
+
+// GetByIds implements repo.UserRepo.
+func (u *UserRepository) GetByIds(ctx context.Context, ids ...int) ([]*dto.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	usersResp := []*dto.User{}
+	for _, id := range ids {
+		if userModel, ok := u.data[id]; ok {
+			usersResp = append(usersResp, converter.UserFromRepo(userModel))
+		}
+	}
+
+	return usersResp, nil
+}
+
+// GetMany implements repo.UserRepo.
+func (u *UserRepository) GetMany(ctx context.Context, usersReq dto.GetUsersRequest) ([]*dto.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	var users []*model.User
+
+	for _, user := range u.data {
+		if usersReq.After == nil || user.Id < *usersReq.After {
+			users = append(users, user)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Id > users[j].Id
+	})
+	if len(users) > usersReq.First {
+		users = users[:usersReq.First+1]
+	}
+	var usersDto []*dto.User
+	for _, user := range users {
+		usersDto = append(usersDto, converter.UserFromRepo(user))
+	}
+
+	if len(usersDto) == 0 {
+		return usersDto, dto.NewCustomError(repo.UsersNotFoundErr, usersReq)
+	}
+
+	return usersDto, nil
+}
+
+// Insert implements repo.UserRepo.
+func (u *UserRepository) Insert(ctx context.Context, newUser dto.NewUser) (*dto.User, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, user := range u.data {
+		if user.Username == newUser.Username {
+			return &dto.User{}, dto.NewCustomError(repo.UsernameTakenErr, newUser)
+		}
+	}
+	userModel := &model.User{
+		Id:        idgen.GenerateId(),
+		Username:  newUser.Username,
+		CreatedAt: time.Now(),
+	}
+	u.data[userModel.Id] = userModel
+	userResp := converter.UserFromRepo(userModel)
+
+	return userResp, nil
+}
@@ -11,5 +11,16 @@ type Comment struct {
 	ArticleID int
 	ParentId  sql.NullInt32
 	CreatedAt time.Time
+	DeletedAt sql.NullTime
+	AuthorId  sql.NullInt32
+	Path      []int
+	SortKey   any
 	Rown      *int
 }
+
+type CommentRevision struct {
+	Id        int
+	CommentId int
+	Text      string
+	CreatedAt time.Time
+}
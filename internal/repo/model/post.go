@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type Post struct {
 	Id        int
@@ -8,4 +11,8 @@ type Post struct {
 	Text      string
 	Closed    bool
 	CreatedAt time.Time
+	ClosedAt  sql.NullTime
+	ClosedBy  sql.NullInt32
+	AuthorId  sql.NullInt32
+	SortKey   any
 }
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type User struct {
+	Id        int
+	Username  string
+	CreatedAt time.Time
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/elusiv0/oz_task/internal/dto"
@@ -12,7 +14,9 @@ import (
 	"github.com/elusiv0/oz_task/internal/repo"
 	"github.com/elusiv0/oz_task/internal/repo/converter"
 	"github.com/elusiv0/oz_task/internal/repo/model"
+	"github.com/elusiv0/oz_task/internal/repo/postgres/order"
 	"github.com/elusiv0/oz_task/pkg/postgres"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 )
 
@@ -36,9 +40,31 @@ func New(
 var _ repo.CommentRepo = &CommentRepository{}
 
 const (
-	commentTable = "comments"
+	commentTable  = "comments"
+	revisionTable = "comment_revisions"
 )
 
+const (
+	foreignKeyViolationCode = "23503"
+	parentConstraint        = "comments_parent_id_fkey"
+	parentPostConstraint    = "comments_parent_post_fkey"
+)
+
+const (
+	searchQuery     = "plainto_tsquery('simple', ?)"
+	searchCondition = "search_vector @@ " + searchQuery
+	searchScore     = "ts_rank(search_vector, " + searchQuery + ")::float8 AS score"
+)
+
+var commentKeys = order.ChildKeys(commentTable, "parent_id")
+
+// visibleCondition hides deleted comments unless they still have replies,
+// so the reply tree under a deleted comment stays reachable.
+var visibleCondition = squirrel.Or{
+	squirrel.Eq{commentTable + ".deleted_at": nil},
+	squirrel.Expr("EXISTS (SELECT 1 FROM " + commentTable + " AS ch WHERE ch.parent_id = " + commentTable + ".id)"),
+}
+
 // Get implements repo.CommentRepo.
 func (c *CommentRepository) Get(ctx context.Context, id int) (*dto.Comment, error) {
 	commentModel := &model.Comment{}
@@ -46,7 +72,7 @@ func (c *CommentRepository) Get(ctx context.Context, id int) (*dto.Comment, erro
 	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
 	logger.Debug("initialize transaction...")
-	tx, err := c.db.PgxPool.Begin(ctx)
+	tx, err := c.db.Begin(ctx)
 	if err != nil {
 		return commentResp, fmt.Errorf("CommentRepository - Get - begin tx: %w", err)
 	}
@@ -64,7 +90,7 @@ func (c *CommentRepository) Get(ctx context.Context, id int) (*dto.Comment, erro
 
 	logger.Debug("building sql...")
 	sql, args, err := c.db.Builder.
-		Select("id", "_text", "article_id", "parent_id", "created_at").
+		Select("id", "_text", "article_id", "parent_id", "created_at", "deleted_at", "author_id", "path").
 		From(commentTable).
 		Where(squirrel.Eq{"id": id}).
 		ToSql()
@@ -78,7 +104,8 @@ func (c *CommentRepository) Get(ctx context.Context, id int) (*dto.Comment, erro
 	err = row.Scan(
 		&commentModel.Id, &commentModel.Text,
 		&commentModel.ArticleID, &commentModel.ParentId,
-		&commentModel.CreatedAt,
+		&commentModel.CreatedAt, &commentModel.DeletedAt,
+		&commentModel.AuthorId, &commentModel.Path,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -96,14 +123,58 @@ func (c *CommentRepository) Get(ctx context.Context, id int) (*dto.Comment, erro
 	return commentResp, nil
 }
 
+// GetByIds implements repo.CommentRepo.
+func (c *CommentRepository) GetByIds(ctx context.Context, ids ...int) ([]*dto.Comment, error) {
+	commentsResp := []*dto.Comment{}
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := c.db.Builder.
+		Select("id", "_text", "article_id", "parent_id", "created_at", "deleted_at", "author_id", "path").
+		From(commentTable).
+		Where(squirrel.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		return commentsResp, fmt.Errorf("CommentRepository - GetByIds - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	rows, err := c.db.Query(ctx, sql, args...)
+	if err != nil {
+		return commentsResp, fmt.Errorf("CommentRepository - GetByIds - query: %w", err)
+	}
+	defer rows.Close()
+	logger.Debug("sql statement was executed successfully")
+
+	for rows.Next() {
+		commentModel := &model.Comment{}
+		err := rows.Scan(
+			&commentModel.Id, &commentModel.Text,
+			&commentModel.ArticleID, &commentModel.ParentId,
+			&commentModel.CreatedAt, &commentModel.DeletedAt,
+			&commentModel.AuthorId, &commentModel.Path,
+		)
+		if err != nil {
+			return commentsResp, fmt.Errorf("CommentRepository - GetByIds - row scan: %w", err)
+		}
+		commentsResp = append(commentsResp, converter.CommentFromRepo(commentModel))
+	}
+
+	return commentsResp, rows.Err()
+}
+
 // GetMany implements repo.CommentRepo.
 func (c *CommentRepository) GetMany(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]*dto.Comment, error) {
 	commentResp := []*dto.Comment{}
+	if !dto.SameBatch(commentsReq...) {
+		return []*dto.Comment{}, fmt.Errorf("CommentRepository - GetMany: requests of a batch must share the filter, the page and the order")
+	}
 	commentModel := &model.Comment{}
 	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
 	logger.Debug("initialize transaction...")
-	tx, err := c.db.PgxPool.Begin(ctx)
+	tx, err := c.db.Begin(ctx)
 	if err != nil {
 		return commentResp, fmt.Errorf("CommentRepository - GetMany - begin tx: %w", err)
 	}
@@ -150,11 +221,18 @@ func (c *CommentRepository) GetMany(ctx context.Context, commentsReq ...dto.GetC
 		}
 		logger.Debug("converting comment model to dto...")
 		curCommentDto := converter.CommentFromRepo(commentModel)
+		// the order is shared by the whole batch, see dto.SameBatch
+		curCommentDto.Cursor = order.Cursor(commentsReq[0].Order, commentModel.Id, commentModel.SortKey)
 		logger.Debug("model was converted successfully")
 
 		commentResp = append(commentResp, curCommentDto)
 	}
 
+	// the batch query already restores the order of the backward pages by com_row
+	if len(commentsReq) == 1 && commentsReq[0].Backward() {
+		slices.Reverse(commentResp)
+	}
+
 	if len(commentResp) == 0 && len(commentsReq) == 1 {
 		cErr := dto.NewCustomError(repo.CommentsNotFoundErr, commentsReq[0])
 
@@ -170,7 +248,7 @@ func (c *CommentRepository) Insert(ctx context.Context, newComment dto.NewCommen
 	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
 	logger.Debug("initialize transaction...")
-	tx, err := c.db.PgxPool.Begin(ctx)
+	tx, err := c.db.Begin(ctx)
 	if err != nil {
 		return &dto.Comment{}, fmt.Errorf("CommentRepository - Insert - begin tx: %w", err)
 	}
@@ -188,11 +266,12 @@ func (c *CommentRepository) Insert(ctx context.Context, newComment dto.NewCommen
 	logger.Debug("building sql...")
 	sql, args, err := c.db.Builder.
 		Insert(commentTable).
-		Columns("_text", "article_id", "parent_id").
+		Columns("_text", "article_id", "parent_id", "author_id", "path").
 		Values(
-			newComment.Text, newComment.ArticleID, newComment.ParentID,
+			newComment.Text, newComment.ArticleID, newComment.ParentID, newComment.AuthorID,
+			squirrel.Expr("COALESCE((SELECT path || id FROM "+commentTable+" WHERE id = ?), '{}')", newComment.ParentID),
 		).
-		Suffix("RETURNING id, _text, article_id, parent_id, created_at").
+		Suffix("RETURNING id, _text, article_id, parent_id, created_at, deleted_at, author_id, path").
 		ToSql()
 	if err != nil {
 		return &dto.Comment{}, fmt.Errorf("CommentRepository - Insert - build sql: %w", err)
@@ -204,9 +283,21 @@ func (c *CommentRepository) Insert(ctx context.Context, newComment dto.NewCommen
 	err = row.Scan(
 		&commentResp.Id, &commentResp.Text,
 		&commentResp.ArticleID, &commentResp.ParentId,
-		&commentResp.CreatedAt,
+		&commentResp.CreatedAt, &commentResp.DeletedAt,
+		&commentResp.AuthorId, &commentResp.Path,
 	)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+			switch pgErr.ConstraintName {
+			case parentConstraint:
+				err = dto.NewCustomError(repo.ParentCommentNotFoundErr, newComment)
+				return &dto.Comment{}, err
+			case parentPostConstraint:
+				err = dto.NewCustomError(repo.ParentCommentMismatchErr, newComment)
+				return &dto.Comment{}, err
+			}
+		}
 		return &dto.Comment{}, fmt.Errorf("CommentRepository - Insert - scanL %w", err)
 	}
 	logger.Debug("sql statement was executed successfully")
@@ -218,50 +309,473 @@ func (c *CommentRepository) Insert(ctx context.Context, newComment dto.NewCommen
 	return commentRespDto, nil
 }
 
+// Edit implements repo.CommentRepo.
+// The previous text is saved into the revision history in the same transaction.
+func (c *CommentRepository) Edit(ctx context.Context, id int, text string) (*dto.Comment, error) {
+	commentResp := &model.Comment{}
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("initialize transaction...")
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Edit - begin tx: %w", err)
+	}
+	logger.Debug("transation was initialized successfully")
+	defer func() {
+		if err != nil {
+			logger.Debug("error was handled, rollback transaction")
+			tx.Rollback(ctx)
+			return
+		}
+		logger.Debug("transaction was committed successfully")
+		err = tx.Commit(ctx)
+	}()
+
+	logger.Debug("building sql...")
+	lockSql, lockArgs, err := c.db.Builder.
+		Select("deleted_at").
+		From(commentTable).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Edit - build sql: %w", err)
+	}
+	revisionSql, revisionArgs, err := c.db.Builder.
+		Insert(revisionTable).
+		Columns("comment_id", "_text").
+		Select(
+			c.db.Builder.
+				Select("id", "_text").
+				From(commentTable).
+				Where(squirrel.Eq{"id": id}),
+		).
+		ToSql()
+	if err != nil {
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Edit - build sql: %w", err)
+	}
+	sql, args, err := c.db.Builder.
+		Update(commentTable).
+		Set("_text", text).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id, _text, article_id, parent_id, created_at, deleted_at, author_id, path").
+		ToSql()
+	if err != nil {
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Edit - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully",
+		slog.String("lock_sql", lockSql), slog.Any("lock_args", lockArgs),
+		slog.String("revision_sql", revisionSql), slog.Any("revision_args", revisionArgs),
+		slog.String("sql", sql), slog.Any("args", args),
+	)
+
+	logger.Debug("executing sql statements...")
+	err = tx.QueryRow(ctx, lockSql, lockArgs...).Scan(&commentResp.DeletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = dto.NewCustomError(repo.CommentNotFoundErr, map[string]int{"id": id})
+			return &dto.Comment{}, err
+		}
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Edit - lock comment: %w", err)
+	}
+	if commentResp.DeletedAt.Valid {
+		err = dto.NewCustomError(repo.CommentDeletedErr, map[string]int{"id": id})
+		return &dto.Comment{}, err
+	}
+	_, err = tx.Exec(ctx, revisionSql, revisionArgs...)
+	if err != nil {
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Edit - insert revision: %w", err)
+	}
+	row := tx.QueryRow(ctx, sql, args...)
+	err = row.Scan(
+		&commentResp.Id, &commentResp.Text,
+		&commentResp.ArticleID, &commentResp.ParentId,
+		&commentResp.CreatedAt, &commentResp.DeletedAt,
+		&commentResp.AuthorId, &commentResp.Path,
+	)
+	if err != nil {
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Edit - scan: %w", err)
+	}
+	logger.Debug("sql statements were executed successfully")
+
+	logger.Debug("converting comment model to dto...")
+	commentRespDto := converter.CommentFromRepo(commentResp)
+	logger.Debug("model was converted successfully")
+
+	return commentRespDto, nil
+}
+
+// Delete implements repo.CommentRepo.
+// The comment is tombstoned instead of being removed, so its replies keep a valid parent.
+func (c *CommentRepository) Delete(ctx context.Context, id int) (*dto.Comment, error) {
+	commentResp := &model.Comment{}
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := c.db.Builder.
+		Update(commentTable).
+		Set("deleted_at", squirrel.Expr("COALESCE(deleted_at, current_timestamp)")).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id, _text, article_id, parent_id, created_at, deleted_at, author_id, path").
+		ToSql()
+	if err != nil {
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Delete - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	row := c.db.QueryRow(ctx, sql, args...)
+	err = row.Scan(
+		&commentResp.Id, &commentResp.Text,
+		&commentResp.ArticleID, &commentResp.ParentId,
+		&commentResp.CreatedAt, &commentResp.DeletedAt,
+		&commentResp.AuthorId, &commentResp.Path,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &dto.Comment{}, dto.NewCustomError(repo.CommentNotFoundErr, map[string]int{"id": id})
+		}
+		return &dto.Comment{}, fmt.Errorf("CommentRepository - Delete - scan: %w", err)
+	}
+	logger.Debug("sql statement was executed successfully")
+
+	logger.Debug("converting comment model to dto...")
+	commentRespDto := converter.CommentFromRepo(commentResp)
+	logger.Debug("model was converted successfully")
+
+	return commentRespDto, nil
+}
+
+// GetRevisions implements repo.CommentRepo.
+func (c *CommentRepository) GetRevisions(ctx context.Context, commentId int) ([]*dto.CommentRevision, error) {
+	revisionsResp := []*dto.CommentRevision{}
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := c.db.Builder.
+		Select("id", "comment_id", "_text", "created_at").
+		From(revisionTable).
+		Where(squirrel.Eq{"comment_id": commentId}).
+		OrderBy("id DESC").
+		ToSql()
+	if err != nil {
+		return revisionsResp, fmt.Errorf("CommentRepository - GetRevisions - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	rows, err := c.db.Query(ctx, sql, args...)
+	if err != nil {
+		return revisionsResp, fmt.Errorf("CommentRepository - GetRevisions - query: %w", err)
+	}
+	defer rows.Close()
+	logger.Debug("sql statement was executed successfully")
+
+	for rows.Next() {
+		revisionModel := &model.CommentRevision{}
+		err := rows.Scan(
+			&revisionModel.Id, &revisionModel.CommentId,
+			&revisionModel.Text, &revisionModel.CreatedAt,
+		)
+		if err != nil {
+			return revisionsResp, fmt.Errorf("CommentRepository - GetRevisions - row scan: %w", err)
+		}
+		revisionsResp = append(revisionsResp, converter.CommentRevisionFromRepo(revisionModel))
+	}
+
+	return revisionsResp, nil
+}
+
+// Count implements repo.CommentRepo.
+// Every request is counted the same way as its comments are selected by GetMany, regardless of the page.
+func (c *CommentRepository) Count(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]int, error) {
+	countsResp := make([]int, len(commentsReq))
+	var postsId, parentsId, authorsId []int
+	for _, val := range commentsReq {
+		if val.AuthorId != nil {
+			authorsId = append(authorsId, *val.AuthorId)
+		} else if val.ParentId != nil {
+			parentsId = append(parentsId, *val.ParentId)
+		} else if val.PostId != nil {
+			postsId = append(postsId, *val.PostId)
+		}
+	}
+
+	byPost, err := c.countBy(ctx, "article_id", postsId, squirrel.Eq{"parent_id": nil}, visibleCondition)
+	if err != nil {
+		return countsResp, fmt.Errorf("CommentRepository - Count: %w", err)
+	}
+	byParent, err := c.countBy(ctx, "parent_id", parentsId, visibleCondition)
+	if err != nil {
+		return countsResp, fmt.Errorf("CommentRepository - Count: %w", err)
+	}
+	byAuthor, err := c.countBy(ctx, "author_id", authorsId, squirrel.Eq{"deleted_at": nil})
+	if err != nil {
+		return countsResp, fmt.Errorf("CommentRepository - Count: %w", err)
+	}
+
+	for idx, val := range commentsReq {
+		if val.AuthorId != nil {
+			countsResp[idx] = byAuthor[*val.AuthorId]
+		} else if val.ParentId != nil {
+			countsResp[idx] = byParent[*val.ParentId]
+		} else if val.PostId != nil {
+			countsResp[idx] = byPost[*val.PostId]
+		}
+	}
+
+	return countsResp, nil
+}
+
+func (c *CommentRepository) countBy(ctx context.Context, column string, ids []int, conditions ...squirrel.Sqlizer) (map[int]int, error) {
+	countsResp := make(map[int]int)
+	if len(ids) == 0 {
+		return countsResp, nil
+	}
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	builder := c.db.Builder.
+		Select(column, "count(*)").
+		From(commentTable).
+		Where(squirrel.Eq{column: ids})
+	for _, condition := range conditions {
+		builder = builder.Where(condition)
+	}
+	sql, args, err := builder.GroupBy(column).ToSql()
+	if err != nil {
+		return countsResp, fmt.Errorf("build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	rows, err := c.db.Query(ctx, sql, args...)
+	if err != nil {
+		return countsResp, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+	logger.Debug("sql statement was executed successfully")
+
+	for rows.Next() {
+		var id, count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return countsResp, fmt.Errorf("row scan: %w", err)
+		}
+		countsResp[id] = count
+	}
+
+	return countsResp, rows.Err()
+}
+
+// Search implements repo.CommentRepo.
+// Deleted comments are not searchable.
+func (c *CommentRepository) Search(ctx context.Context, query string, limit int) ([]*dto.SearchResult, error) {
+	resultsResp := []*dto.SearchResult{}
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := c.db.Builder.
+		Select("id", "_text", "article_id", "parent_id", "created_at", "deleted_at", "author_id", "path").
+		Column(searchScore, query).
+		From(commentTable).
+		Where(searchCondition, query).
+		Where(squirrel.Eq{"deleted_at": nil}).
+		OrderBy("score DESC", "id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return resultsResp, fmt.Errorf("CommentRepository - Search - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	rows, err := c.db.Query(ctx, sql, args...)
+	if err != nil {
+		return resultsResp, fmt.Errorf("CommentRepository - Search - query: %w", err)
+	}
+	defer rows.Close()
+	logger.Debug("sql statement was executed successfully")
+
+	for rows.Next() {
+		commentModel := &model.Comment{}
+		var score float64
+		err := rows.Scan(
+			&commentModel.Id, &commentModel.Text,
+			&commentModel.ArticleID, &commentModel.ParentId,
+			&commentModel.CreatedAt, &commentModel.DeletedAt,
+			&commentModel.AuthorId, &commentModel.Path, &score,
+		)
+		if err != nil {
+			return resultsResp, fmt.Errorf("CommentRepository - Search - row scan: %w", err)
+		}
+		resultsResp = append(resultsResp, &dto.SearchResult{
+			Score:   score,
+			Comment: converter.CommentFromRepo(commentModel),
+		})
+	}
+
+	return resultsResp, nil
+}
+
+// Thread implements repo.CommentRepo.
+// The subtree is walked by a recursive CTE over parent_id and returned in depth-first order,
+// replies follow their parent from the oldest one. One extra node is selected to detect truncation.
+func (c *CommentRepository) Thread(ctx context.Context, threadReq dto.ThreadRequest) ([]*dto.ThreadNode, error) {
+	nodesResp := []*dto.ThreadNode{}
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	columns := []string{
+		commentTable + ".id", commentTable + "._text", commentTable + ".article_id", commentTable + ".parent_id",
+		commentTable + ".created_at", commentTable + ".deleted_at", commentTable + ".author_id", commentTable + ".path",
+	}
+	// the parts of the CTE are nested into the statement, so their placeholders are numbered by it
+	anchor := c.db.Builder.
+		PlaceholderFormat(squirrel.Question).
+		Select(columns...).
+		Column("0 AS depth").
+		Column("ARRAY[" + commentTable + ".id] AS thread_path").
+		From(commentTable).
+		Where(visibleCondition)
+	if threadReq.CommentId != nil {
+		anchor = anchor.Where(squirrel.Eq{commentTable + ".id": threadReq.CommentId})
+	} else {
+		anchor = anchor.Where(squirrel.Eq{
+			commentTable + ".article_id": threadReq.PostId,
+			commentTable + ".parent_id":  nil,
+		})
+	}
+	replies := c.db.Builder.
+		PlaceholderFormat(squirrel.Question).
+		Select(columns...).
+		Column("thread.depth + 1").
+		Column("thread.thread_path || " + commentTable + ".id").
+		From(commentTable).
+		Join("thread ON " + commentTable + ".parent_id = thread.id").
+		Where(squirrel.Lt{"thread.depth": threadReq.MaxDepth}).
+		Where(visibleCondition)
+	sql, args, err := c.db.Builder.
+		Select("id", "_text", "article_id", "parent_id", "created_at", "deleted_at", "author_id", "path", "depth").
+		PrefixExpr(squirrel.Expr("WITH RECURSIVE thread AS (? UNION ALL ?)", anchor, replies)).
+		From("thread").
+		OrderBy("thread_path").
+		Limit(uint64(threadReq.MaxNodes + 1)).
+		ToSql()
+	if err != nil {
+		return nodesResp, fmt.Errorf("CommentRepository - Thread - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	rows, err := c.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nodesResp, fmt.Errorf("CommentRepository - Thread - query: %w", err)
+	}
+	defer rows.Close()
+	logger.Debug("sql statement was executed successfully")
+
+	for rows.Next() {
+		commentModel := &model.Comment{}
+		var depth int
+		err := rows.Scan(
+			&commentModel.Id, &commentModel.Text,
+			&commentModel.ArticleID, &commentModel.ParentId,
+			&commentModel.CreatedAt, &commentModel.DeletedAt,
+			&commentModel.AuthorId, &commentModel.Path, &depth,
+		)
+		if err != nil {
+			return nodesResp, fmt.Errorf("CommentRepository - Thread - row scan: %w", err)
+		}
+		nodesResp = append(nodesResp, &dto.ThreadNode{
+			Depth:   depth,
+			Comment: converter.CommentFromRepo(commentModel),
+		})
+	}
+
+	if len(nodesResp) == 0 {
+		return nodesResp, dto.NewCustomError(repo.CommentsNotFoundErr, threadReq)
+	}
+
+	return nodesResp, nil
+}
+
 func (c *CommentRepository) buildManyNonVariadic(commentResp *model.Comment, commentsReq dto.GetCommentsRequest) (*squirrel.SelectBuilder, []any) {
 	var conditions squirrel.And
 	scanRows := []any{
 		&commentResp.Id, &commentResp.Text,
 		&commentResp.ArticleID, &commentResp.ParentId,
-		&commentResp.CreatedAt,
+		&commentResp.CreatedAt, &commentResp.DeletedAt,
+		&commentResp.AuthorId, &commentResp.Path,
+	}
+	if commentsReq.AuthorId != nil {
+		conditions = append(conditions, squirrel.Eq{"author_id": commentsReq.AuthorId})
+		conditions = append(conditions, squirrel.Eq{"deleted_at": nil})
+	} else {
+		conditions = append(conditions, squirrel.Eq{"parent_id": commentsReq.ParentId})
+		conditions = append(conditions, visibleCondition)
 	}
-	conditions = append(conditions, squirrel.Eq{"parent_id": commentsReq.ParentId})
 	if commentsReq.PostId != nil {
 		conditions = append(conditions, squirrel.Eq{"article_id": commentsReq.PostId})
 	}
 	if commentsReq.After != nil {
-		conditions = append(conditions, squirrel.Lt{"id": commentsReq.After})
+		conditions = append(conditions, commentKeys.After(*commentsReq.After))
+	}
+	if commentsReq.Before != nil {
+		conditions = append(conditions, commentKeys.Before(*commentsReq.Before))
 	}
 	builder := c.db.Builder.
-		Select("id", "_text", "article_id", "parent_id", "created_at").
+		Select("id", "_text", "article_id", "parent_id", "created_at", "deleted_at", "author_id", "path").
 		From(commentTable)
+	if sortKey := commentKeys.Column(commentsReq.Order); sortKey != "" {
+		builder = builder.Column(sortKey)
+		scanRows = append(scanRows, &commentResp.SortKey)
+	}
 	if len(conditions) > 0 {
 		builder = builder.Where(conditions)
 	}
+	limit := commentsReq.First
+	if commentsReq.Backward() {
+		limit = commentsReq.Last
+	}
 	builder = builder.
-		OrderBy("id DESC").
-		Limit(uint64(commentsReq.First + 1))
+		OrderBy(commentKeys.OrderBy(commentsReq.Order, commentsReq.Backward())...).
+		Limit(uint64(limit + 1))
 	return &builder, scanRows
 }
 
 func (c *CommentRepository) buildManyVariadic(commentResp *model.Comment, commentsReq ...dto.GetCommentsRequest) (*squirrel.SelectBuilder, []any) {
 	var parentsId []*int
 	var postsId []*int
+	var authorsId []*int
 	scanRows := []any{
 		&commentResp.Id, &commentResp.Text,
 		&commentResp.ArticleID, &commentResp.ParentId,
-		&commentResp.CreatedAt, &commentResp.Rown,
+		&commentResp.CreatedAt, &commentResp.DeletedAt,
+		&commentResp.AuthorId, &commentResp.Path,
 	}
 	partition := "parent_id"
-	if commentsReq[0].ParentId != nil {
+	if commentsReq[0].AuthorId != nil {
+		authorsId = make([]*int, 0, len(commentsReq))
+		partition = "author_id"
+	} else if commentsReq[0].ParentId != nil {
 		parentsId = make([]*int, 10)
 	} else {
 		postsId = make([]*int, 10)
 		partition = "article_id"
 	}
-	first := commentsReq[0].First
+	page := commentsReq[0].Page
+	limit := page.First
+	rowOrder := "com_row"
+	if page.Backward() {
+		limit = page.Last
+		rowOrder = "com_row DESC"
+	}
+	orderBy := strings.Join(commentKeys.OrderBy(commentsReq[0].Order, page.Backward()), ", ")
 
 	for _, val := range commentsReq {
+		if authorsId != nil {
+			authorsId = append(authorsId, val.AuthorId)
+		}
 		if parentsId != nil {
 			parentsId = append(parentsId, val.ParentId)
 		}
@@ -270,7 +784,11 @@ func (c *CommentRepository) buildManyVariadic(commentResp *model.Comment, commen
 		}
 	}
 	var conditions squirrel.And
-	if parentsId != nil {
+	// the comments of an author are the same as selected by buildManyNonVariadic, replies included
+	if authorsId != nil {
+		conditions = append(conditions, squirrel.Eq{"author_id": authorsId})
+		conditions = append(conditions, squirrel.Eq{"deleted_at": nil})
+	} else if parentsId != nil {
 		conditions = append(conditions, squirrel.Eq{"parent_id": parentsId})
 	} else {
 		conditions = append(conditions, squirrel.Eq{"parent_id": nil})
@@ -278,15 +796,32 @@ func (c *CommentRepository) buildManyVariadic(commentResp *model.Comment, commen
 	if postsId != nil {
 		conditions = append(conditions, squirrel.Eq{"article_id": postsId})
 	}
+	if page.After != nil {
+		conditions = append(conditions, commentKeys.After(*page.After))
+	}
+	if page.Before != nil {
+		conditions = append(conditions, commentKeys.Before(*page.Before))
+	}
+	if authorsId == nil {
+		conditions = append(conditions, visibleCondition)
+	}
+	columns := []string{"id", "_text", "article_id", "parent_id", "created_at", "deleted_at", "author_id", "path"}
 	subSelect := c.db.Builder.
-		Select("id", "_text",
-			"article_id", "parent_id",
-			"created_at", "row_number() OVER (PARTITION BY "+partition+" ORDER BY id DESC) AS com_row").
+		Select(columns...).
 		From(commentTable).
 		Where(conditions)
+	if sortKey := commentKeys.Column(commentsReq[0].Order); sortKey != "" {
+		subSelect = subSelect.Column(sortKey)
+		columns = append(columns, "sort_key")
+		scanRows = append(scanRows, &commentResp.SortKey)
+	}
+	subSelect = subSelect.Column("row_number() OVER (PARTITION BY " + partition + " ORDER BY " + orderBy + ") AS com_row")
+	columns = append(columns, "com_row")
+	scanRows = append(scanRows, &commentResp.Rown)
 	builder := c.db.Builder.
-		Select("id", "_text", "article_id", "parent_id", "created_at", "com_row").
+		Select(columns...).
 		FromSelect(subSelect, "com").
-		Where(squirrel.LtOrEq{"com.com_row": first})
+		Where(squirrel.LtOrEq{"com.com_row": limit + 1}).
+		OrderBy(partition, rowOrder)
 	return &builder, scanRows
 }
@@ -0,0 +1,119 @@
+package order
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+// Keys builds sql expressions of the sort keys for the row of the table with the given alias.
+type Keys struct {
+	Table    string
+	Replies  func(alias string) string
+	Activity func(alias string) string
+}
+
+// ChildKeys returns sort keys counting the visible comments, which reference the row by the column.
+func ChildKeys(table string, column string) Keys {
+	return Keys{
+		Table: table,
+		Replies: func(alias string) string {
+			return fmt.Sprintf(
+				"(SELECT count(*) FROM comments AS ch WHERE ch.%s = %s.id AND ch.deleted_at IS NULL)",
+				column, alias,
+			)
+		},
+		Activity: func(alias string) string {
+			return fmt.Sprintf(
+				"GREATEST(%s.created_at, (SELECT max(ch.created_at) FROM comments AS ch WHERE ch.%s = %s.id AND ch.deleted_at IS NULL))",
+				alias, column, alias,
+			)
+		},
+	}
+}
+
+func (k Keys) key(order dto.Order, alias string) string {
+	switch order {
+	case dto.OrderMostReplies:
+		return k.Replies(alias)
+	case dto.OrderRecentActivity:
+		return k.Activity(alias)
+	}
+
+	return ""
+}
+
+// OrderBy returns the ORDER BY clauses of the order,
+// backward pages are selected in the reversed order starting from the before cursor.
+func (k Keys) OrderBy(order dto.Order, backward bool) []string {
+	desc, asc := "DESC", "ASC"
+	if backward {
+		desc, asc = asc, desc
+	}
+	switch order {
+	case dto.OrderOldest:
+		return []string{"id " + asc}
+	case dto.OrderMostReplies, dto.OrderRecentActivity:
+		return []string{k.key(order, k.Table) + " " + desc, "id " + desc}
+	}
+
+	return []string{"id " + desc}
+}
+
+// Column returns the sort key selected with the row, it is empty when the order needs nothing but id.
+func (k Keys) Column(order dto.Order) string {
+	key := k.key(order, k.Table)
+	if key == "" {
+		return ""
+	}
+
+	return key + " AS sort_key"
+}
+
+// After returns the condition selecting rows placed after the cursor.
+func (k Keys) After(cursor dto.Cursor) squirrel.Sqlizer {
+	return k.compare(cursor, "<")
+}
+
+// Before returns the condition selecting rows placed before the cursor.
+func (k Keys) Before(cursor dto.Cursor) squirrel.Sqlizer {
+	return k.compare(cursor, ">")
+}
+
+// compare compares rows with the cursor in the descending order, the oldest first order is the opposite one.
+func (k Keys) compare(cursor dto.Cursor, op string) squirrel.Sqlizer {
+	switch cursor.Order {
+	case dto.OrderOldest:
+		if op == "<" {
+			op = ">"
+		} else {
+			op = "<"
+		}
+		return squirrel.Expr("id "+op+" ?", cursor.Id)
+	case dto.OrderMostReplies:
+		return squirrel.Expr("("+k.key(cursor.Order, k.Table)+", id) "+op+" (?, ?)", *cursor.Replies, cursor.Id)
+	case dto.OrderRecentActivity:
+		return squirrel.Expr("("+k.key(cursor.Order, k.Table)+", id) "+op+" (?, ?)", *cursor.Activity, cursor.Id)
+	}
+
+	return squirrel.Expr("id "+op+" ?", cursor.Id)
+}
+
+// Cursor returns the cursor of the row from the sort key selected with it.
+func Cursor(order dto.Order, id int, sortKey any) *dto.Cursor {
+	cursor := &dto.Cursor{
+		Order: order,
+		Id:    id,
+	}
+	switch key := sortKey.(type) {
+	case int64:
+		replies := int(key)
+		cursor.Replies = &replies
+	case time.Time:
+		cursor.Activity = &key
+	}
+
+	return cursor
+}
@@ -0,0 +1,107 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+)
+
+func TestKeys(t *testing.T) {
+	keys := ChildKeys("posts", "article_id")
+	replies := "(SELECT count(*) FROM comments AS ch WHERE ch.article_id = posts.id AND ch.deleted_at IS NULL)"
+	activity := "GREATEST(posts.created_at, (SELECT max(ch.created_at) FROM comments AS ch WHERE ch.article_id = posts.id AND ch.deleted_at IS NULL))"
+	count := 2
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		cursor   dto.Cursor
+		orderBy  []string
+		backward []string
+		column   string
+		after    string
+		before   string
+		args     []any
+	}{
+		{
+			cursor:   dto.Cursor{Order: dto.OrderNewest, Id: 7},
+			orderBy:  []string{"id DESC"},
+			backward: []string{"id ASC"},
+			after:    "id < ?",
+			before:   "id > ?",
+			args:     []any{7},
+		},
+		{
+			cursor:   dto.Cursor{Order: dto.OrderOldest, Id: 7},
+			orderBy:  []string{"id ASC"},
+			backward: []string{"id DESC"},
+			after:    "id > ?",
+			before:   "id < ?",
+			args:     []any{7},
+		},
+		{
+			cursor:   dto.Cursor{Order: dto.OrderMostReplies, Id: 7, Replies: &count},
+			orderBy:  []string{replies + " DESC", "id DESC"},
+			backward: []string{replies + " ASC", "id ASC"},
+			column:   replies + " AS sort_key",
+			after:    "(" + replies + ", id) < (?, ?)",
+			before:   "(" + replies + ", id) > (?, ?)",
+			args:     []any{2, 7},
+		},
+		{
+			cursor:   dto.Cursor{Order: dto.OrderRecentActivity, Id: 7, Activity: &at},
+			orderBy:  []string{activity + " DESC", "id DESC"},
+			backward: []string{activity + " ASC", "id ASC"},
+			column:   activity + " AS sort_key",
+			after:    "(" + activity + ", id) < (?, ?)",
+			before:   "(" + activity + ", id) > (?, ?)",
+			args:     []any{at, 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.cursor.Order), func(t *testing.T) {
+			order := tt.cursor.Order
+			if got := keys.OrderBy(order, false); !reflect.DeepEqual(got, tt.orderBy) {
+				t.Errorf("order by: got %q, want %q", got, tt.orderBy)
+			}
+			if got := keys.OrderBy(order, true); !reflect.DeepEqual(got, tt.backward) {
+				t.Errorf("backward order by: got %q, want %q", got, tt.backward)
+			}
+			if got := keys.Column(order); got != tt.column {
+				t.Errorf("column: got %q, want %q", got, tt.column)
+			}
+
+			sql, args, err := keys.After(tt.cursor).ToSql()
+			if err != nil || sql != tt.after || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("after: got %q %v %v, want %q %v", sql, args, err, tt.after, tt.args)
+			}
+			sql, args, err = keys.Before(tt.cursor).ToSql()
+			if err != nil || sql != tt.before || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("before: got %q %v %v, want %q %v", sql, args, err, tt.before, tt.args)
+			}
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	count := 2
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		order   dto.Order
+		sortKey any
+		want    dto.Cursor
+	}{
+		{dto.OrderNewest, nil, dto.Cursor{Order: dto.OrderNewest, Id: 7}},
+		{dto.OrderOldest, nil, dto.Cursor{Order: dto.OrderOldest, Id: 7}},
+		{dto.OrderMostReplies, int64(2), dto.Cursor{Order: dto.OrderMostReplies, Id: 7, Replies: &count}},
+		{dto.OrderRecentActivity, at, dto.Cursor{Order: dto.OrderRecentActivity, Id: 7, Activity: &at}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			if got := Cursor(tt.order, 7, tt.sortKey); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/elusiv0/oz_task/internal/dto"
@@ -12,6 +14,7 @@ import (
 	"github.com/elusiv0/oz_task/internal/repo"
 	"github.com/elusiv0/oz_task/internal/repo/converter"
 	"github.com/elusiv0/oz_task/internal/repo/model"
+	"github.com/elusiv0/oz_task/internal/repo/postgres/order"
 	"github.com/elusiv0/oz_task/pkg/postgres"
 	"github.com/jackc/pgx/v4"
 )
@@ -39,6 +42,14 @@ const (
 	postTable = "posts"
 )
 
+var postKeys = order.ChildKeys(postTable, "article_id")
+
+const (
+	searchQuery     = "plainto_tsquery('simple', ?)"
+	searchCondition = "search_vector @@ " + searchQuery
+	searchScore     = "ts_rank(search_vector, " + searchQuery + ")::float8 AS score"
+)
+
 // Get implements repo.PostRepo.
 func (p *PostRepository) Get(ctx context.Context, id int) (*dto.Post, error) {
 	postModel := &model.Post{}
@@ -46,7 +57,7 @@ func (p *PostRepository) Get(ctx context.Context, id int) (*dto.Post, error) {
 	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
 	logger.Debug("initialize transaction...")
-	tx, err := p.db.PgxPool.Begin(ctx)
+	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return postResp, fmt.Errorf("PostRepository - Get - begin tx: %w", err)
 	}
@@ -63,7 +74,7 @@ func (p *PostRepository) Get(ctx context.Context, id int) (*dto.Post, error) {
 
 	logger.Debug("building sql...")
 	sql, args, err := p.db.Builder.
-		Select("id", "title", "_text", "closed", "created_at").
+		Select("id", "title", "_text", "closed", "created_at", "closed_at", "closed_by", "author_id").
 		From(postTable).
 		Where(squirrel.Eq{"id": id}).
 		ToSql()
@@ -77,7 +88,8 @@ func (p *PostRepository) Get(ctx context.Context, id int) (*dto.Post, error) {
 	err = row.Scan(
 		&postModel.Id, &postModel.Title,
 		&postModel.Text, &postModel.Closed,
-		&postModel.CreatedAt,
+		&postModel.CreatedAt, &postModel.ClosedAt,
+		&postModel.ClosedBy, &postModel.AuthorId,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -95,12 +107,16 @@ func (p *PostRepository) Get(ctx context.Context, id int) (*dto.Post, error) {
 }
 
 // GetMany implements repo.PostRepo.
-func (p *PostRepository) GetMany(ctx context.Context, postsReq dto.GetPostsRequest) ([]*dto.Post, error) {
+// A batch of requests by author is fetched by one query, every author gets its own page.
+func (p *PostRepository) GetMany(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]*dto.Post, error) {
 	postResp := []*dto.Post{}
+	if !dto.SameBatch(postsReq...) || (len(postsReq) > 1 && postsReq[0].AuthorId == nil) {
+		return postResp, fmt.Errorf("PostRepository - GetMany: requests of a batch must be by author and share the page and the order")
+	}
 	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
 	logger.Debug("initialize transaction...")
-	tx, err := p.db.PgxPool.Begin(ctx)
+	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return postResp, fmt.Errorf("PostRepository - GetMany - begin tx: %w", err)
 	}
@@ -116,15 +132,53 @@ func (p *PostRepository) GetMany(ctx context.Context, postsReq dto.GetPostsReque
 	}()
 
 	logger.Debug("building sql...")
+	page, ord := postsReq[0].Page, postsReq[0].Order
+	columns := []string{"id", "title", "_text", "closed", "created_at", "closed_at", "closed_by", "author_id"}
 	builder := p.db.Builder.
-		Select("id", "title", "_text", "closed", "created_at").
+		Select(columns...).
 		From(postTable)
-	if postsReq.After != nil {
-		builder = builder.Where(squirrel.Lt{"id": postsReq.After})
+	sortKey := postKeys.Column(ord)
+	if sortKey != "" {
+		builder = builder.Column(sortKey)
+		columns = append(columns, "sort_key")
 	}
-	sql, args, err := builder.OrderBy("id DESC").
-		Limit(uint64(postsReq.First + 1)).
-		ToSql()
+	if page.After != nil {
+		builder = builder.Where(postKeys.After(*page.After))
+	}
+	if page.Before != nil {
+		builder = builder.Where(postKeys.Before(*page.Before))
+	}
+	if len(postsReq) > 1 {
+		authorsId := make([]int, len(postsReq))
+		for idx, val := range postsReq {
+			authorsId[idx] = *val.AuthorId
+		}
+		builder = builder.Where(squirrel.Eq{"author_id": authorsId})
+	} else if postsReq[0].AuthorId != nil {
+		builder = builder.Where(squirrel.Eq{"author_id": postsReq[0].AuthorId})
+	}
+	limit := page.First
+	if page.Backward() {
+		limit = page.Last
+	}
+	orderBy := postKeys.OrderBy(ord, page.Backward())
+	if len(postsReq) > 1 {
+		// the rows are numbered in the page order, the backward pages are restored by post_row
+		rowOrder := "post_row"
+		if page.Backward() {
+			rowOrder = "post_row DESC"
+		}
+		builder = builder.Column("row_number() OVER (PARTITION BY author_id ORDER BY " + strings.Join(orderBy, ", ") + ") AS post_row")
+		builder = p.db.Builder.
+			Select(columns...).
+			FromSelect(builder, "post").
+			Where(squirrel.LtOrEq{"post.post_row": limit + 1}).
+			OrderBy("author_id", rowOrder)
+	} else {
+		builder = builder.OrderBy(orderBy...).
+			Limit(uint64(limit + 1))
+	}
+	sql, args, err := builder.ToSql()
 	if err != nil {
 		return postResp, fmt.Errorf("PostRepository - GetMany - build sql: %w", err)
 	}
@@ -140,24 +194,37 @@ func (p *PostRepository) GetMany(ctx context.Context, postsReq dto.GetPostsReque
 
 	for rows.Next() {
 		currPost := &model.Post{}
-
-		err := rows.Scan(
+		scanRows := []any{
 			&currPost.Id, &currPost.Title,
 			&currPost.Text, &currPost.Closed,
-			&currPost.CreatedAt,
-		)
+			&currPost.CreatedAt, &currPost.ClosedAt,
+			&currPost.ClosedBy, &currPost.AuthorId,
+		}
+		if sortKey != "" {
+			scanRows = append(scanRows, &currPost.SortKey)
+		}
+
+		err := rows.Scan(scanRows...)
 		if err != nil {
 			return postResp, fmt.Errorf("PostRepository - GetMany - row scan: %w", err)
 		}
 
 		logger.Debug("converting comment model to dto...")
 		currPostDto := converter.PostFromRepo(currPost)
+		currPostDto.Cursor = order.Cursor(ord, currPost.Id, currPost.SortKey)
 		logger.Debug("model was converted successfully")
 		postResp = append(postResp, currPostDto)
 	}
+	if err = rows.Err(); err != nil {
+		return postResp, fmt.Errorf("PostRepository - GetMany - rows: %w", err)
+	}
+
+	if len(postsReq) == 1 && page.Backward() {
+		slices.Reverse(postResp)
+	}
 
-	if len(postResp) == 0 {
-		return postResp, dto.NewCustomError(repo.PostsNotFoundErr, postsReq)
+	if len(postResp) == 0 && len(postsReq) == 1 {
+		return postResp, dto.NewCustomError(repo.PostsNotFoundErr, postsReq[0])
 	}
 
 	return postResp, nil
@@ -169,7 +236,7 @@ func (p *PostRepository) Insert(ctx context.Context, newPost dto.NewPost) (*dto.
 	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
 	logger.Debug("initialize transaction...")
-	tx, err := p.db.PgxPool.Begin(ctx)
+	tx, err := p.db.Begin(ctx)
 	if err != nil {
 		return &dto.Post{}, fmt.Errorf("PostRepository - Insert - begin tx: %w", err)
 	}
@@ -185,13 +252,17 @@ func (p *PostRepository) Insert(ctx context.Context, newPost dto.NewPost) (*dto.
 	}()
 
 	logger.Debug("building sql...")
+	var closedAt any
+	if newPost.Closed {
+		closedAt = squirrel.Expr("current_timestamp")
+	}
 	sql, args, err := p.db.Builder.
 		Insert(postTable).
-		Columns("title", "_text", "closed").
+		Columns("title", "_text", "closed", "closed_at", "author_id").
 		Values(
-			newPost.Title, newPost.Text, newPost.Closed,
+			newPost.Title, newPost.Text, newPost.Closed, closedAt, newPost.AuthorID,
 		).
-		Suffix("RETURNING id, title, _text, closed, created_at").
+		Suffix("RETURNING id, title, _text, closed, created_at, closed_at, closed_by, author_id").
 		ToSql()
 	if err != nil {
 		return &dto.Post{}, fmt.Errorf("PostRepository - Insert - build sql: %w", err)
@@ -203,7 +274,8 @@ func (p *PostRepository) Insert(ctx context.Context, newPost dto.NewPost) (*dto.
 	err = row.Scan(
 		&postResp.Id, &postResp.Title,
 		&postResp.Text, &postResp.Closed,
-		&postResp.CreatedAt,
+		&postResp.CreatedAt, &postResp.ClosedAt,
+		&postResp.ClosedBy, &postResp.AuthorId,
 	)
 	if err != nil {
 		return &dto.Post{}, fmt.Errorf("CommentRepository - Insert - scanL %w", err)
@@ -216,3 +288,318 @@ func (p *PostRepository) Insert(ctx context.Context, newPost dto.NewPost) (*dto.
 
 	return postRespDto, nil
 }
+
+// Update implements repo.PostRepo.
+func (p *PostRepository) Update(ctx context.Context, id int, updatePost dto.UpdatePost) (*dto.Post, error) {
+	postResp := &model.Post{}
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("initialize transaction...")
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return &dto.Post{}, fmt.Errorf("PostRepository - Update - begin tx: %w", err)
+	}
+	logger.Debug("transation was initialized successfully")
+	defer func() {
+		if err != nil {
+			logger.Debug("error was handled, rollback transaction")
+			tx.Rollback(ctx)
+			return
+		}
+		logger.Debug("transaction was committed successfully")
+		err = tx.Commit(ctx)
+	}()
+
+	logger.Debug("building sql...")
+	setMap := map[string]any{}
+	if updatePost.Title != nil {
+		setMap["title"] = *updatePost.Title
+	}
+	if updatePost.Text != nil {
+		setMap["_text"] = *updatePost.Text
+	}
+	sql, args, err := p.db.Builder.
+		Update(postTable).
+		SetMap(setMap).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id, title, _text, closed, created_at, closed_at, closed_by, author_id").
+		ToSql()
+	if err != nil {
+		return &dto.Post{}, fmt.Errorf("PostRepository - Update - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	row := tx.QueryRow(ctx, sql, args...)
+	err = row.Scan(
+		&postResp.Id, &postResp.Title,
+		&postResp.Text, &postResp.Closed,
+		&postResp.CreatedAt, &postResp.ClosedAt,
+		&postResp.ClosedBy, &postResp.AuthorId,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = dto.NewCustomError(repo.PostNotFoundErr, id)
+			return &dto.Post{}, err
+		}
+		return &dto.Post{}, fmt.Errorf("PostRepository - Update - scan: %w", err)
+	}
+	logger.Debug("sql statement was executed successfully")
+
+	logger.Debug("converting post model to dto...")
+	postRespDto := converter.PostFromRepo(postResp)
+	logger.Debug("model was converted successfully")
+
+	return postRespDto, nil
+}
+
+// Delete implements repo.PostRepo.
+// Comments of the post are deleted in the same transaction,
+// so no comment is left referencing a missing post.
+func (p *PostRepository) Delete(ctx context.Context, id int) (*dto.Post, error) {
+	postResp := &model.Post{}
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("initialize transaction...")
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return &dto.Post{}, fmt.Errorf("PostRepository - Delete - begin tx: %w", err)
+	}
+	logger.Debug("transation was initialized successfully")
+	defer func() {
+		if err != nil {
+			logger.Debug("error was handled, rollback transaction")
+			tx.Rollback(ctx)
+			return
+		}
+		logger.Debug("transaction was committed successfully")
+		err = tx.Commit(ctx)
+	}()
+
+	logger.Debug("building sql...")
+	// comments are deleted by ON DELETE CASCADE
+	sql, args, err := p.db.Builder.
+		Delete(postTable).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id, title, _text, closed, created_at, closed_at, closed_by, author_id").
+		ToSql()
+	if err != nil {
+		return &dto.Post{}, fmt.Errorf("PostRepository - Delete - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	row := tx.QueryRow(ctx, sql, args...)
+	err = row.Scan(
+		&postResp.Id, &postResp.Title,
+		&postResp.Text, &postResp.Closed,
+		&postResp.CreatedAt, &postResp.ClosedAt,
+		&postResp.ClosedBy, &postResp.AuthorId,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = dto.NewCustomError(repo.PostNotFoundErr, id)
+			return &dto.Post{}, err
+		}
+		return &dto.Post{}, fmt.Errorf("PostRepository - Delete - scan: %w", err)
+	}
+	logger.Debug("sql statement was executed successfully")
+
+	logger.Debug("converting post model to dto...")
+	postRespDto := converter.PostFromRepo(postResp)
+	logger.Debug("model was converted successfully")
+
+	return postRespDto, nil
+}
+
+// Close implements repo.PostRepo.
+func (p *PostRepository) Close(ctx context.Context, id int, closedBy int) (*dto.Post, error) {
+	postResp, err := p.setClosed(ctx, id, map[string]any{
+		"closed":    true,
+		"closed_at": squirrel.Expr("current_timestamp"),
+		"closed_by": closedBy,
+	})
+	if err != nil {
+		return postResp, fmt.Errorf("PostRepository - Close: %w", err)
+	}
+
+	return postResp, nil
+}
+
+// Reopen implements repo.PostRepo.
+func (p *PostRepository) Reopen(ctx context.Context, id int) (*dto.Post, error) {
+	postResp, err := p.setClosed(ctx, id, map[string]any{
+		"closed":    false,
+		"closed_at": nil,
+		"closed_by": nil,
+	})
+	if err != nil {
+		return postResp, fmt.Errorf("PostRepository - Reopen: %w", err)
+	}
+
+	return postResp, nil
+}
+
+func (p *PostRepository) setClosed(ctx context.Context, id int, setMap map[string]any) (*dto.Post, error) {
+	postResp := &model.Post{}
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := p.db.Builder.
+		Update(postTable).
+		SetMap(setMap).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING id, title, _text, closed, created_at, closed_at, closed_by, author_id").
+		ToSql()
+	if err != nil {
+		return &dto.Post{}, fmt.Errorf("build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	row := p.db.QueryRow(ctx, sql, args...)
+	err = row.Scan(
+		&postResp.Id, &postResp.Title,
+		&postResp.Text, &postResp.Closed,
+		&postResp.CreatedAt, &postResp.ClosedAt,
+		&postResp.ClosedBy, &postResp.AuthorId,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &dto.Post{}, dto.NewCustomError(repo.PostNotFoundErr, id)
+		}
+		return &dto.Post{}, fmt.Errorf("scan: %w", err)
+	}
+	logger.Debug("sql statement was executed successfully")
+
+	logger.Debug("converting post model to dto...")
+	postRespDto := converter.PostFromRepo(postResp)
+	logger.Debug("model was converted successfully")
+
+	return postRespDto, nil
+}
+
+// Count implements repo.PostRepo.
+// Requests without an author count all of the posts.
+func (p *PostRepository) Count(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]int, error) {
+	countsResp := make([]int, len(postsReq))
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+	var authorsId []int
+	countAll := false
+	for _, val := range postsReq {
+		if val.AuthorId != nil {
+			authorsId = append(authorsId, *val.AuthorId)
+		} else {
+			countAll = true
+		}
+	}
+
+	total := 0
+	if countAll {
+		logger.Debug("building sql...")
+		sql, args, err := p.db.Builder.
+			Select("count(*)").
+			From(postTable).
+			ToSql()
+		if err != nil {
+			return countsResp, fmt.Errorf("PostRepository - Count - build sql: %w", err)
+		}
+		logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+		logger.Debug("executing sql statement...")
+		if err := p.db.QueryRow(ctx, sql, args...).Scan(&total); err != nil {
+			return countsResp, fmt.Errorf("PostRepository - Count - row scan: %w", err)
+		}
+		logger.Debug("sql statement was executed successfully")
+	}
+
+	byAuthor := make(map[int]int)
+	if len(authorsId) > 0 {
+		logger.Debug("building sql...")
+		sql, args, err := p.db.Builder.
+			Select("author_id", "count(*)").
+			From(postTable).
+			Where(squirrel.Eq{"author_id": authorsId}).
+			GroupBy("author_id").
+			ToSql()
+		if err != nil {
+			return countsResp, fmt.Errorf("PostRepository - Count - build sql: %w", err)
+		}
+		logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+		logger.Debug("executing sql statement...")
+		rows, err := p.db.Query(ctx, sql, args...)
+		if err != nil {
+			return countsResp, fmt.Errorf("PostRepository - Count - query: %w", err)
+		}
+		defer rows.Close()
+		logger.Debug("sql statement was executed successfully")
+
+		for rows.Next() {
+			var authorId, count int
+			if err := rows.Scan(&authorId, &count); err != nil {
+				return countsResp, fmt.Errorf("PostRepository - Count - row scan: %w", err)
+			}
+			byAuthor[authorId] = count
+		}
+	}
+
+	for idx, val := range postsReq {
+		if val.AuthorId != nil {
+			countsResp[idx] = byAuthor[*val.AuthorId]
+		} else {
+			countsResp[idx] = total
+		}
+	}
+
+	return countsResp, nil
+}
+
+// Search implements repo.PostRepo.
+func (p *PostRepository) Search(ctx context.Context, query string, limit int) ([]*dto.SearchResult, error) {
+	resultsResp := []*dto.SearchResult{}
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := p.db.Builder.
+		Select("id", "title", "_text", "closed", "created_at", "closed_at", "closed_by", "author_id").
+		Column(searchScore, query).
+		From(postTable).
+		Where(searchCondition, query).
+		OrderBy("score DESC", "id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return resultsResp, fmt.Errorf("PostRepository - Search - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	rows, err := p.db.Query(ctx, sql, args...)
+	if err != nil {
+		return resultsResp, fmt.Errorf("PostRepository - Search - query: %w", err)
+	}
+	defer rows.Close()
+	logger.Debug("sql statement was executed successfully")
+
+	for rows.Next() {
+		postModel := &model.Post{}
+		var score float64
+		err := rows.Scan(
+			&postModel.Id, &postModel.Title,
+			&postModel.Text, &postModel.Closed,
+			&postModel.CreatedAt, &postModel.ClosedAt,
+			&postModel.ClosedBy, &postModel.AuthorId,
+			&score,
+		)
+		if err != nil {
+			return resultsResp, fmt.Errorf("PostRepository - Search - row scan: %w", err)
+		}
+		resultsResp = append(resultsResp, &dto.SearchResult{
+			Score: score,
+			Post:  converter.PostFromRepo(postModel),
+		})
+	}
+
+	return resultsResp, nil
+}
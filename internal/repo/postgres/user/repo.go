@@ -0,0 +1,202 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/middleware"
+	"github.com/elusiv0/oz_task/internal/repo"
+	"github.com/elusiv0/oz_task/internal/repo/converter"
+	"github.com/elusiv0/oz_task/internal/repo/model"
+	"github.com/elusiv0/oz_task/pkg/postgres"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type UserRepository struct {
+	db     *postgres.Postgres
+	logger *slog.Logger
+}
+
+func New(
+	postgres *postgres.Postgres,
+	logger *slog.Logger,
+) *UserRepository {
+	repo := &UserRepository{
+		db:     postgres,
+		logger: logger,
+	}
+
+	return repo
+}
+
+var _ repo.UserRepo = &UserRepository{}
+
+const (
+	userTable = "users"
+
+	uniqueViolationCode = "23505"
+)
+
+// Get implements repo.UserRepo.
+func (u *UserRepository) Get(ctx context.Context, id int) (*dto.User, error) {
+	userModel := &model.User{}
+	logger := u.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := u.db.Builder.
+		Select("id", "username", "created_at").
+		From(userTable).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return &dto.User{}, fmt.Errorf("UserRepository - Get - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	row := u.db.QueryRow(ctx, sql, args...)
+	err = row.Scan(
+		&userModel.Id, &userModel.Username,
+		&userModel.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &dto.User{}, dto.NewCustomError(repo.UserNotFoundErr, id)
+		}
+		return &dto.User{}, fmt.Errorf("UserRepository - Get - scan: %w", err)
+	}
+	logger.Debug("sql statement was executed successfully")
+
+	logger.Debug("converting user model to dto...")
+	userResp := converter.UserFromRepo(userModel)
+	logger.Debug("model was converted successfully")
+
+	return userResp, nil
+}
+
+// GetByIds implements repo.UserRepo.
+func (u *UserRepository) GetByIds(ctx context.Context, ids ...int) ([]*dto.User, error) {
+	usersResp := []*dto.User{}
+	logger := u.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := u.db.Builder.
+		Select("id", "username", "created_at").
+		From(userTable).
+		Where(squirrel.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		return usersResp, fmt.Errorf("UserRepository - GetByIds - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	usersResp, err = u.query(ctx, sql, args...)
+	if err != nil {
+		return usersResp, fmt.Errorf("UserRepository - GetByIds: %w", err)
+	}
+	logger.Debug("sql statement was executed successfully")
+
+	return usersResp, nil
+}
+
+// GetMany implements repo.UserRepo.
+func (u *UserRepository) GetMany(ctx context.Context, usersReq dto.GetUsersRequest) ([]*dto.User, error) {
+	usersResp := []*dto.User{}
+	logger := u.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	builder := u.db.Builder.
+		Select("id", "username", "created_at").
+		From(userTable)
+	if usersReq.After != nil {
+		builder = builder.Where(squirrel.Lt{"id": usersReq.After})
+	}
+	sql, args, err := builder.OrderBy("id DESC").
+		Limit(uint64(usersReq.First + 1)).
+		ToSql()
+	if err != nil {
+		return usersResp, fmt.Errorf("UserRepository - GetMany - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	usersResp, err = u.query(ctx, sql, args...)
+	if err != nil {
+		return usersResp, fmt.Errorf("UserRepository - GetMany: %w", err)
+	}
+	logger.Debug("sql statement was executed successfully")
+
+	if len(usersResp) == 0 {
+		return usersResp, dto.NewCustomError(repo.UsersNotFoundErr, usersReq)
+	}
+
+	return usersResp, nil
+}
+
+// Insert implements repo.UserRepo.
+func (u *UserRepository) Insert(ctx context.Context, newUser dto.NewUser) (*dto.User, error) {
+	userModel := &model.User{}
+	logger := u.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("building sql...")
+	sql, args, err := u.db.Builder.
+		Insert(userTable).
+		Columns("username").
+		Values(newUser.Username).
+		Suffix("RETURNING id, username, created_at").
+		ToSql()
+	if err != nil {
+		return &dto.User{}, fmt.Errorf("UserRepository - Insert - build sql: %w", err)
+	}
+	logger.Debug("sql was builded successfully", slog.String("sql", sql), slog.Any("args", args))
+
+	logger.Debug("executing sql statement...")
+	row := u.db.QueryRow(ctx, sql, args...)
+	err = row.Scan(
+		&userModel.Id, &userModel.Username,
+		&userModel.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return &dto.User{}, dto.NewCustomError(repo.UsernameTakenErr, newUser)
+		}
+		return &dto.User{}, fmt.Errorf("UserRepository - Insert - scan: %w", err)
+	}
+	logger.Debug("sql statement was executed successfully")
+
+	logger.Debug("converting user model to dto...")
+	userResp := converter.UserFromRepo(userModel)
+	logger.Debug("model was converted successfully")
+
+	return userResp, nil
+}
+
+func (u *UserRepository) query(ctx context.Context, sql string, args ...any) ([]*dto.User, error) {
+	usersResp := []*dto.User{}
+	rows, err := u.db.Query(ctx, sql, args...)
+	if err != nil {
+		return usersResp, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		userModel := &model.User{}
+		err := rows.Scan(
+			&userModel.Id, &userModel.Username,
+			&userModel.CreatedAt,
+		)
+		if err != nil {
+			return usersResp, fmt.Errorf("row scan: %w", err)
+		}
+		usersResp = append(usersResp, converter.UserFromRepo(userModel))
+	}
+
+	return usersResp, rows.Err()
+}
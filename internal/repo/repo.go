@@ -16,16 +16,68 @@ var (
 		ErrorMessage: "comments not found",
 		StatusCode:   http.StatusNoContent,
 	}
+	CommentNotFoundErr = dto.ErrInfo{
+		ErrorMessage: "comment with provided id not found",
+		StatusCode:   http.StatusNotFound,
+	}
+	CommentDeletedErr = dto.ErrInfo{
+		ErrorMessage: "comment with provided id was deleted",
+		StatusCode:   http.StatusGone,
+	}
+	ParentCommentNotFoundErr = dto.ErrInfo{
+		ErrorMessage: "couldn't get parent comment with provided id",
+		StatusCode:   http.StatusBadRequest,
+	}
+	ParentCommentMismatchErr = dto.ErrInfo{
+		ErrorMessage: "parent comment belongs to another post",
+		StatusCode:   http.StatusBadRequest,
+	}
+	UsersNotFoundErr = dto.ErrInfo{
+		ErrorMessage: "users not found",
+		StatusCode:   http.StatusNoContent,
+	}
+	UserNotFoundErr = dto.ErrInfo{
+		ErrorMessage: "user with provided id not found",
+		StatusCode:   http.StatusNotFound,
+	}
+	UsernameTakenErr = dto.ErrInfo{
+		ErrorMessage: "user with provided username already exists",
+		StatusCode:   http.StatusConflict,
+	}
+	PostNotFoundErr = dto.ErrInfo{
+		ErrorMessage: "post with provided id not found",
+		StatusCode:   http.StatusNotFound,
+	}
 )
 
 type PostRepo interface {
-	GetMany(ctx context.Context, postsReq dto.GetPostsRequest) ([]*dto.Post, error)
+	GetMany(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]*dto.Post, error)
 	Insert(ctx context.Context, newPost dto.NewPost) (*dto.Post, error)
 	Get(ctx context.Context, id int) (*dto.Post, error)
+	Update(ctx context.Context, id int, updatePost dto.UpdatePost) (*dto.Post, error)
+	Delete(ctx context.Context, id int) (*dto.Post, error)
+	Close(ctx context.Context, id int, closedBy int) (*dto.Post, error)
+	Reopen(ctx context.Context, id int) (*dto.Post, error)
+	Count(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]int, error)
+	Search(ctx context.Context, query string, limit int) ([]*dto.SearchResult, error)
 }
 
 type CommentRepo interface {
 	GetMany(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]*dto.Comment, error)
+	GetByIds(ctx context.Context, ids ...int) ([]*dto.Comment, error)
 	Insert(ctx context.Context, newComment dto.NewComment) (*dto.Comment, error)
 	Get(ctx context.Context, id int) (*dto.Comment, error)
+	Edit(ctx context.Context, id int, text string) (*dto.Comment, error)
+	Delete(ctx context.Context, id int) (*dto.Comment, error)
+	GetRevisions(ctx context.Context, commentId int) ([]*dto.CommentRevision, error)
+	Count(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]int, error)
+	Search(ctx context.Context, query string, limit int) ([]*dto.SearchResult, error)
+	Thread(ctx context.Context, threadReq dto.ThreadRequest) ([]*dto.ThreadNode, error)
+}
+
+type UserRepo interface {
+	GetMany(ctx context.Context, usersReq dto.GetUsersRequest) ([]*dto.User, error)
+	GetByIds(ctx context.Context, ids ...int) ([]*dto.User, error)
+	Insert(ctx context.Context, newUser dto.NewUser) (*dto.User, error)
+	Get(ctx context.Context, id int) (*dto.User, error)
 }
@@ -1,15 +1,26 @@
 package gql
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/elusiv0/oz_task/internal/config"
 	"github.com/elusiv0/oz_task/internal/graph"
+	"github.com/elusiv0/oz_task/internal/graph/complexity"
 	"github.com/elusiv0/oz_task/internal/graph/middleware"
+	"github.com/elusiv0/oz_task/internal/metrics"
+	reqmiddleware "github.com/elusiv0/oz_task/internal/middleware"
 	"github.com/elusiv0/oz_task/internal/service"
+	"github.com/elusiv0/oz_task/pkg/auth"
+	"github.com/elusiv0/oz_task/pkg/drain"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,12 +33,77 @@ func InitRoutes(
 	router *gin.Engine,
 	graphConfig graph.Config,
 	commentService service.CommentService,
+	postService service.PostService,
+	userService service.UserService,
+	verifier *auth.Verifier,
+	metrics *metrics.Metrics,
+	limits *reqmiddleware.RateLimits,
+	limitsConf config.Graphql,
+	draining *drain.Drain,
 ) {
-	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graphConfig))
-	srv.Use(extension.FixedComplexityLimit(1500))
+	srv := newServer(graph.NewExecutableSchema(graphConfig), verifier, draining)
+	// depth is checked first, a deep query is rejected with its own error rather than as too complex
+	srv.Use(complexity.DepthLimit{Limit: limitsConf.MaxDepth})
+	srv.Use(extension.FixedComplexityLimit(limitsConf.MaxComplexity))
 	srv.AroundResponses(middleware.ResponseMiddleware(logger))
+	srv.Use(middleware.MetricsExtension{Metrics: metrics})
+	srv.Use(middleware.TracingExtension{})
+	srv.Use(middleware.SubscriptionCloseExtension{})
+	handlers := []gin.HandlerFunc{
+		reqmiddleware.TracingMiddleware(),
+		reqmiddleware.AuthMiddleware(verifier),
+	}
+	// limits are nil when the rate limiting is disabled
+	if limits != nil {
+		srv.Use(middleware.RateLimitExtension{Limits: limits})
+		handlers = append(handlers, reqmiddleware.RateLimitMiddleware(limits))
+	}
 	router.GET("/", playgroundHandler(playground.Handler("GraphQL playground", "/query")))
-	router.Any("/query", graphqlHandler(middleware.DataloaderMiddleware(commentService, srv)))
+	handlers = append(handlers, graphqlHandler(middleware.DataloaderMiddleware(commentService, postService, userService, metrics, srv)))
+	router.Any("/query", handlers...)
+}
+
+// newServer is handler.NewDefaultServer with websocket transport
+// authenticating subscriptions by the connection_init payload.
+func newServer(es graphql.ExecutableSchema, verifier *auth.Verifier, draining *drain.Drain) *handler.Server {
+	srv := handler.New(es)
+
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		InitFunc:              websocketInit(verifier, draining),
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New(1000))
+
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New(100),
+	})
+
+	return srv
+}
+
+// websocketInit authenticates the connection and ties it to the draining context:
+// once the app drains, the connection is closed with a close frame and its subscriptions are completed.
+func websocketInit(verifier *auth.Verifier, draining *drain.Drain) transport.WebsocketInitFunc {
+	return func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		ctx = draining.Track(ctx)
+
+		authorization := initPayload.Authorization()
+		if authorization == "" {
+			return ctx, nil, nil
+		}
+		ctx, err := reqmiddleware.Authenticate(ctx, verifier, authorization)
+		if err != nil {
+			return ctx, nil, err
+		}
+
+		return ctx, nil, nil
+	}
 }
 
 func graphqlHandler(h http.Handler) gin.HandlerFunc {
@@ -0,0 +1,141 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elusiv0/oz_task/pkg/drain"
+	"github.com/elusiv0/oz_task/pkg/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	statusOk          = "ok"
+	statusUnavailable = "unavailable"
+
+	pingTimeout = time.Second
+	// saturationWait is the average wait for a connection between two probes over which the pool is saturated,
+	// pools smaller than saturationMinPool are expected to make requests wait and are judged by the ping only.
+	saturationWait    = 500 * time.Millisecond
+	saturationMinPool = 4
+)
+
+type check struct {
+	Status string     `json:"status"`
+	Error  string     `json:"error,omitempty"`
+	Pool   *poolStats `json:"pool,omitempty"`
+}
+
+type poolStats struct {
+	Acquired      int32   `json:"acquired"`
+	Idle          int32   `json:"idle"`
+	Total         int32   `json:"total"`
+	Max           int32   `json:"max"`
+	AcquireWaitMs float64 `json:"acquire_wait_ms"`
+}
+
+// acquireWindow keeps the acquire counters of the previous probe,
+// so the wait is averaged over the acquires made since then rather than since the start.
+type acquireWindow struct {
+	mu       sync.Mutex
+	count    int64
+	duration time.Duration
+}
+
+// wait returns the average duration of the acquires made since the previous call.
+func (w *acquireWindow) wait(count int64, duration time.Duration) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	acquires, waited := count-w.count, duration-w.duration
+	w.count, w.duration = count, duration
+	if acquires <= 0 {
+		return 0
+	}
+
+	return waited / time.Duration(acquires)
+}
+
+type readiness struct {
+	Status string           `json:"status"`
+	Checks map[string]check `json:"checks"`
+}
+
+// livenessHandler answers as long as the process serves http.
+func livenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status": statusOk,
+		})
+	}
+}
+
+// readinessHandler reports whether the instance can take traffic, pg is nil for the in-memory db.
+func readinessHandler(pg *postgres.Postgres, draining *drain.Drain) gin.HandlerFunc {
+	window := &acquireWindow{}
+	return func(c *gin.Context) {
+		resp := readiness{
+			Status: statusOk,
+			Checks: map[string]check{
+				"draining": checkDraining(draining),
+			},
+		}
+		if pg != nil {
+			resp.Checks["postgres"] = checkPostgres(c.Request.Context(), pg, window)
+		}
+
+		code := http.StatusOK
+		for _, check := range resp.Checks {
+			if check.Status != statusOk {
+				resp.Status = statusUnavailable
+				code = http.StatusServiceUnavailable
+			}
+		}
+		c.JSON(code, resp)
+	}
+}
+
+func checkDraining(draining *drain.Drain) check {
+	if draining.Draining() {
+		return check{Status: statusUnavailable, Error: "app is shutting down"}
+	}
+
+	return check{Status: statusOk}
+}
+
+// checkPostgres fails when the pool is saturated, requests wait for a connection longer than saturationWait
+// on average since the previous probe, or when the ping fails. A busy pool isn't saturated by itself,
+// the ping then waits for a free connection until its timeout.
+func checkPostgres(ctx context.Context, pg *postgres.Postgres, window *acquireWindow) check {
+	stat := pg.PgxPool.Stat()
+	wait := window.wait(stat.AcquireCount(), stat.AcquireDuration())
+	resp := check{
+		Status: statusOk,
+		Pool: &poolStats{
+			Acquired:      stat.AcquiredConns(),
+			Idle:          stat.IdleConns(),
+			Total:         stat.TotalConns(),
+			Max:           stat.MaxConns(),
+			AcquireWaitMs: float64(wait) / float64(time.Millisecond),
+		},
+	}
+	if saturated(stat.MaxConns(), wait) {
+		resp.Status = statusUnavailable
+		resp.Error = "connection pool is saturated"
+		return resp
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := pg.PgxPool.Ping(ctx); err != nil {
+		resp.Status = statusUnavailable
+		resp.Error = "ping: " + err.Error()
+	}
+
+	return resp
+}
+
+func saturated(maxConns int32, wait time.Duration) bool {
+	return maxConns >= saturationMinPool && wait > saturationWait
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolSaturation(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxConns int32
+		probes   [][2]int64 // acquire count and duration of each probe, the last one is judged
+		want     bool
+	}{
+		{"no acquires", 10, [][2]int64{{0, 0}}, false},
+		{"short waits", 10, [][2]int64{{100, int64(100 * time.Millisecond)}}, false},
+		{"long waits", 10, [][2]int64{{10, int64(10 * time.Second)}}, true},
+		{"small pool", 1, [][2]int64{{10, int64(10 * time.Second)}}, false},
+		{"waits before the previous probe", 10, [][2]int64{
+			{10, int64(10 * time.Second)},
+			{110, int64(10*time.Second + 100*time.Millisecond)},
+		}, false},
+		{"no acquires since the previous probe", 10, [][2]int64{
+			{10, int64(10 * time.Second)},
+			{10, int64(10 * time.Second)},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := &acquireWindow{}
+			var wait time.Duration
+			for _, probe := range tt.probes {
+				wait = window.wait(probe[0], time.Duration(probe[1]))
+			}
+			if got := saturated(tt.maxConns, wait); got != tt.want {
+				t.Errorf("saturated(%d, %s) = %v, want %v", tt.maxConns, wait, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,18 @@
 package router
 
 import (
+	"fmt"
 	"log/slog"
-	"net/http"
 
+	"github.com/elusiv0/oz_task/internal/config"
 	"github.com/elusiv0/oz_task/internal/graph"
+	"github.com/elusiv0/oz_task/internal/metrics"
 	"github.com/elusiv0/oz_task/internal/middleware"
 	"github.com/elusiv0/oz_task/internal/router/gql"
 	"github.com/elusiv0/oz_task/internal/service"
+	"github.com/elusiv0/oz_task/pkg/auth"
+	"github.com/elusiv0/oz_task/pkg/drain"
+	"github.com/elusiv0/oz_task/pkg/postgres"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,16 +20,27 @@ func InitRoutes(
 	logger *slog.Logger,
 	gqlConf graph.Config,
 	commentService service.CommentService,
-) *gin.Engine {
+	postService service.PostService,
+	userService service.UserService,
+	verifier *auth.Verifier,
+	pg *postgres.Postgres,
+	metrics *metrics.Metrics,
+	limits *middleware.RateLimits,
+	limitsConf config.Graphql,
+	trustedProxies []string,
+	draining *drain.Drain,
+) (*gin.Engine, error) {
 	router := gin.New()
+	// nil trusts no proxy, so X-Forwarded-For can't forge the address of a client
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("Router - InitRoutes - set trusted proxies: %w", err)
+	}
 	router.Use(middleware.RequestMiddleware())
-	router.GET("/ping", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"ping": "pong",
-		})
-	})
+	router.GET("/healthz", livenessHandler())
+	router.GET("/readyz", readinessHandler(pg, draining))
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
-	gql.InitRoutes(logger, router, gqlConf, commentService)
+	gql.InitRoutes(logger, router, gqlConf, commentService, postService, userService, verifier, metrics, limits, limitsConf, draining)
 
-	return router
+	return router, nil
 }
@@ -0,0 +1,199 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elusiv0/oz_task/internal/config"
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/graph"
+	"github.com/elusiv0/oz_task/internal/graph/directive"
+	resolver "github.com/elusiv0/oz_task/internal/graph/resolver"
+	"github.com/elusiv0/oz_task/internal/metrics"
+	imCommentRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/comment"
+	imPostRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/post"
+	imUserRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/user"
+	commentService "github.com/elusiv0/oz_task/internal/service/comment"
+	postService "github.com/elusiv0/oz_task/internal/service/post"
+	searchService "github.com/elusiv0/oz_task/internal/service/search"
+	userService "github.com/elusiv0/oz_task/internal/service/user"
+	"github.com/elusiv0/oz_task/pkg/auth"
+	"github.com/elusiv0/oz_task/pkg/drain"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+)
+
+type wsMessage struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// TestSubscriptionClosed closes the post of a newComments subscription and checks
+// the subscriber gets the reason with the closer and the time before the complete message.
+func TestSubscriptionClosed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	commentRepo := imCommentRepo.New(logger)
+	postRepo := imPostRepo.New(commentRepo, logger)
+	userRepo := imUserRepo.New(logger)
+	author, err := userRepo.Insert(ctx, dto.NewUser{Username: "author"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := postRepo.Insert(ctx, dto.NewPost{Title: "title", Text: "text", AuthorID: &author.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := commentService.New(commentRepo, 32, logger)
+	ps := postService.New(postRepo, logger)
+	us := userService.New(userRepo, logger)
+	m := metrics.New()
+	gConfig := graph.Config{
+		Resolvers: resolver.NewResolver(cs, ps, us, searchService.New(postRepo, commentRepo, logger), m, logger),
+	}
+	gConfig.Directives.Auth = directive.Auth()
+	gConfig.Directives.Owner = directive.Owner(ps, cs)
+	verifier, err := auth.New(auth.HS256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := config.Graphql{MaxComplexity: 1500, MaxDepth: 15}
+	router, err := InitRoutes(logger, gConfig, cs, ps, us, verifier, nil, m, nil, limits, nil, drain.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the handlers are awaited, so no span of the websocket request ends after the test
+	handlers := sync.WaitGroup{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlers.Add(1)
+		defer handlers.Done()
+		router.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		handlers.Wait()
+	})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": strconv.Itoa(author.ID), "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/query", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	send := func(msg wsMessage) {
+		if err := conn.WriteJSON(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receive := func() wsMessage {
+		for {
+			msg := wsMessage{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Type != "ping" && msg.Type != "pong" {
+				return msg
+			}
+		}
+	}
+
+	send(wsMessage{Type: "connection_init"})
+	if msg := receive(); msg.Type != "connection_ack" {
+		t.Fatalf("got %q, want connection_ack", msg.Type)
+	}
+	subscription, _ := json.Marshal(map[string]string{
+		"query": fmt.Sprintf("subscription { newComments(postId: %d) { id } }", post.ID),
+	})
+	send(wsMessage{Id: "1", Type: "subscribe", Payload: subscription})
+
+	// the subscriber is registered once the resolver returns, closing the post earlier would reject it
+	closed := false
+	for start := time.Now(); !closed && time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		closed = closePost(t, srv.URL, token, post.ID, m)
+	}
+	if !closed {
+		t.Fatal("subscription wasn't registered")
+	}
+
+	msg := receive()
+	if msg.Type != "next" {
+		t.Fatalf("got %q, want next: %s", msg.Type, msg.Payload)
+	}
+	payload := struct {
+		Errors []struct {
+			Extensions struct {
+				StatusCode int `json:"status_code"`
+				Request    struct {
+					PostId   int        `json:"postId"`
+					ClosedBy *int       `json:"closedBy"`
+					ClosedAt *time.Time `json:"closedAt"`
+				} `json:"request"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}{}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Errors) != 1 {
+		t.Fatalf("got %d errors, want 1: %s", len(payload.Errors), msg.Payload)
+	}
+	reason := payload.Errors[0].Extensions
+	if reason.StatusCode != http.StatusGone || reason.Request.PostId != post.ID {
+		t.Errorf("got status %d for post %d, want %d for post %d", reason.StatusCode, reason.Request.PostId, http.StatusGone, post.ID)
+	}
+	if reason.Request.ClosedBy == nil || *reason.Request.ClosedBy != author.ID || reason.Request.ClosedAt == nil {
+		t.Errorf("close reason has no closer or time: %s", msg.Payload)
+	}
+	if msg := receive(); msg.Type != "complete" {
+		t.Fatalf("got %q, want complete", msg.Type)
+	}
+}
+
+// closePost closes the post once the subscription is counted by the metrics, it reports whether it did.
+func closePost(t *testing.T, url string, token string, postID int, m *metrics.Metrics) bool {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "graphql_active_subscriptions{") {
+		return false
+	}
+
+	body := strings.NewReader(fmt.Sprintf(`{"query":"mutation { closePost(id: %d) { id } }"}`, postID))
+	req, err := http.NewRequest(http.MethodPost, url+"/query", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("close post: status %d", resp.StatusCode)
+	}
+
+	return true
+}
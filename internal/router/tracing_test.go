@@ -0,0 +1,260 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elusiv0/oz_task/internal/config"
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/graph"
+	"github.com/elusiv0/oz_task/internal/graph/complexity"
+	resolver "github.com/elusiv0/oz_task/internal/graph/resolver"
+	"github.com/elusiv0/oz_task/internal/metrics"
+	imCommentRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/comment"
+	imPostRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/post"
+	imUserRepo "github.com/elusiv0/oz_task/internal/repo/in-memory/user"
+	pgCommentRepo "github.com/elusiv0/oz_task/internal/repo/postgres/comment"
+	commentService "github.com/elusiv0/oz_task/internal/service/comment"
+	postService "github.com/elusiv0/oz_task/internal/service/post"
+	searchService "github.com/elusiv0/oz_task/internal/service/search"
+	userService "github.com/elusiv0/oz_task/internal/service/user"
+	"github.com/elusiv0/oz_task/pkg/auth"
+	"github.com/elusiv0/oz_task/pkg/drain"
+	"github.com/elusiv0/oz_task/pkg/postgres"
+	"github.com/elusiv0/oz_task/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgproto3/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTracingSpans runs one operation through the router and checks the tree of its spans:
+// posts are read from the in-memory repo, comments through the dataloader from postgres.
+func TestTracingSpans(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	exporter := installTracing(t)
+	exporter.Reset()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	host, port := fakePostgres(t)
+	pg, err := postgres.New(postgres.NewConnectionConfig(host, port, "user", "password", "db"), logger, postgres.ConnAttempts(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pg.PgxPool.Close)
+
+	postRepo := imPostRepo.New(imCommentRepo.New(logger), logger)
+	commentRepo := pgCommentRepo.New(pg, logger)
+	if _, err := postRepo.Insert(ctx, dto.NewPost{Title: "title", Text: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	cs := commentService.New(commentRepo, 32, logger)
+	ps := postService.New(postRepo, logger)
+	us := userService.New(imUserRepo.New(logger), logger)
+	m := metrics.New()
+	gConfig := graph.Config{
+		Resolvers: resolver.NewResolver(cs, ps, us, searchService.New(postRepo, commentRepo, logger), m, logger),
+	}
+	complexity.Apply(&gConfig.Complexity)
+	verifier, err := auth.New(auth.HS256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := config.Graphql{MaxComplexity: 1500, MaxDepth: 15}
+	router, err := InitRoutes(logger, gConfig, cs, ps, us, verifier, pg, m, nil, limits, nil, drain.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"query":"query Posts { posts { edges { node { comments { edges { node { id } } } } } } }"}`
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	resp := struct {
+		Extensions struct {
+			RequestId string `json:"request_id"`
+		} `json:"extensions"`
+	}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %s: %v", rec.Body.String(), err)
+	}
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub)
+	bySpanId := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		byName[span.Name] = span
+		bySpanId[span.SpanContext.SpanID().String()] = span
+	}
+	find := func(name string) tracetest.SpanStub {
+		span, ok := byName[name]
+		if !ok {
+			names := []string{}
+			for _, span := range spans {
+				names = append(names, span.Name)
+			}
+			t.Fatalf("span %q is missing, got %v", name, names)
+		}
+		return span
+	}
+	// descends reports whether the ancestor is on the chain of parents of the span
+	descends := func(span, ancestor tracetest.SpanStub) bool {
+		for span.Parent.IsValid() {
+			if span.Parent.SpanID() == ancestor.SpanContext.SpanID() {
+				return true
+			}
+			parent, ok := bySpanId[span.Parent.SpanID().String()]
+			if !ok {
+				return false
+			}
+			span = parent
+		}
+		return false
+	}
+
+	request := find("POST /query")
+	operation := find("query Posts")
+	posts := find("Query.posts")
+	comments := find("Post.comments")
+	batch := find("dataloader comment")
+	sql := find("postgres SELECT")
+
+	for _, span := range spans {
+		if span.SpanContext.TraceID() != request.SpanContext.TraceID() {
+			t.Errorf("span %q has trace %s, want %s", span.Name, span.SpanContext.TraceID(), request.SpanContext.TraceID())
+		}
+	}
+	if operation.Parent.SpanID() != request.SpanContext.SpanID() {
+		t.Errorf("operation span is not a child of the request span")
+	}
+	if !descends(posts, operation) || !descends(comments, operation) {
+		t.Errorf("field spans don't descend from the operation span")
+	}
+	if !descends(batch, request) {
+		t.Errorf("dataloader span doesn't descend from the request span")
+	}
+	if sql.Parent.SpanID() != batch.SpanContext.SpanID() {
+		t.Errorf("sql span is not a child of the dataloader span")
+	}
+	if !hasAttribute(sql.Attributes, "db.statement") {
+		t.Errorf("sql span has no db.statement")
+	}
+
+	if resp.Extensions.RequestId == "" {
+		t.Fatalf("response has no request_id: %s", rec.Body.String())
+	}
+	for _, span := range []tracetest.SpanStub{request, operation} {
+		if requestId := attributeValue(span.Attributes, "request_id"); requestId != resp.Extensions.RequestId {
+			t.Errorf("span %q has request_id %q, want %q", span.Name, requestId, resp.Extensions.RequestId)
+		}
+	}
+}
+
+var (
+	tracingOnce  sync.Once
+	tracingErr   error
+	spanExporter = tracetest.NewInMemoryExporter()
+)
+
+// installTracing sets the global provider once per process: the tracers of the packages
+// are package variables, they delegate only to the first provider installed.
+func installTracing(t *testing.T) *tracetest.InMemoryExporter {
+	tracingOnce.Do(func() {
+		_, tracingErr = tracing.New(context.Background(), tracing.Exporter(spanExporter))
+	})
+	if tracingErr != nil {
+		t.Fatal(tracingErr)
+	}
+
+	return spanExporter
+}
+
+func hasAttribute(attrs []attribute.KeyValue, key string) bool {
+	return attributeValue(attrs, key) != ""
+}
+
+func attributeValue(attrs []attribute.KeyValue, key string) string {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+// fakePostgres serves the postgres protocol far enough for the pool to connect and open
+// transactions, statements are rejected so the repos fail after their sql spans are started.
+func fakePostgres(t *testing.T) (host string, port string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakePostgres(conn)
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(ln.Addr().String())
+
+	return host, port
+}
+
+func serveFakePostgres(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(pgproto3.NewChunkReader(conn), conn)
+	for {
+		msg, err := backend.ReceiveStartupMessage()
+		if err != nil {
+			return
+		}
+		if _, ok := msg.(*pgproto3.SSLRequest); ok {
+			if _, err := conn.Write([]byte("N")); err != nil {
+				return
+			}
+			continue
+		}
+		break
+	}
+	ready := &pgproto3.ReadyForQuery{TxStatus: 'I'}
+	if backend.Send(&pgproto3.AuthenticationOk{}) != nil || backend.Send(ready) != nil {
+		return
+	}
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			// begin and rollback of the repo transactions
+			tag, _, _ := strings.Cut(strings.TrimSpace(msg.String), " ")
+			if backend.Send(&pgproto3.CommandComplete{CommandTag: []byte(strings.ToUpper(tag))}) != nil || backend.Send(ready) != nil {
+				return
+			}
+		case *pgproto3.Sync:
+			errResp := &pgproto3.ErrorResponse{Severity: "ERROR", Code: "0A000", Message: "statements are not supported"}
+			if backend.Send(errResp) != nil || backend.Send(ready) != nil {
+				return
+			}
+		case *pgproto3.Terminate:
+			return
+		}
+	}
+}
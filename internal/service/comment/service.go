@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 
 	"github.com/elusiv0/oz_task/internal/dto"
 	"github.com/elusiv0/oz_task/internal/middleware"
@@ -11,17 +12,40 @@ import (
 	"github.com/elusiv0/oz_task/internal/service"
 )
 
+// MaxThreadNodes bounds the number of comments returned by a single thread request.
+const MaxThreadNodes = 1000
+
+var (
+	InvalidThreadRootErr = dto.ErrInfo{
+		ErrorMessage: "thread requires exactly one of commentId and postId",
+		StatusCode:   http.StatusBadRequest,
+	}
+	InvalidThreadLimitErr = dto.ErrInfo{
+		ErrorMessage: fmt.Sprintf("maxDepth must not be negative, maxNodes must be between 1 and %d", MaxThreadNodes),
+		StatusCode:   http.StatusBadRequest,
+	}
+	CommentDepthExceededErr = dto.ErrInfo{
+		ErrorMessage: "comment is nested deeper than allowed",
+		StatusCode:   http.StatusBadRequest,
+	}
+)
+
 type CommentService struct {
 	commentRepo repo.CommentRepo
+	maxDepth    int
 	logger      *slog.Logger
 }
 
+// New creates the comment service, replies are allowed down to maxDepth,
+// top-level comments have depth 0.
 func New(
 	commentRepo repo.CommentRepo,
+	maxDepth int,
 	logger *slog.Logger,
 ) *CommentService {
 	return &CommentService{
 		commentRepo: commentRepo,
+		maxDepth:    maxDepth,
 		logger:      logger,
 	}
 }
@@ -32,7 +56,7 @@ var _ service.CommentService = &CommentService{}
 func (c *CommentService) Get(ctx context.Context, id int) (*dto.Comment, error) {
 	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
-	logger.Debug("", slog.StringValue("calling comment repo..."))
+	logger.Debug("calling comment repo...")
 	commentResp, err := c.commentRepo.Get(ctx, id)
 	if err != nil {
 		return commentResp, fmt.Errorf("CommentService - Get: %w", err)
@@ -54,10 +78,43 @@ func (c *CommentService) GetMany(ctx context.Context, commentReq ...dto.GetComme
 	return commentResp, nil
 }
 
+// GetByIds implements service.CommentService.
+func (c *CommentService) GetByIds(ctx context.Context, ids ...int) ([]*dto.Comment, error) {
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment repo...")
+	commentsResp, err := c.commentRepo.GetByIds(ctx, ids...)
+	if err != nil {
+		return commentsResp, fmt.Errorf("CommentService - GetByIds: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return commentsResp, nil
+}
+
 // Insert implements service.CommentRepo.
+// A reply is rejected when its parent is missing or belongs to another post
+// or when it would be nested deeper than the configured max depth.
 func (c *CommentService) Insert(ctx context.Context, newComment dto.NewComment) (*dto.Comment, error) {
 	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
+	if newComment.ParentID != nil {
+		logger.Debug("checking parent comment...")
+		parentsResp, err := c.commentRepo.GetByIds(ctx, *newComment.ParentID)
+		if err != nil {
+			return &dto.Comment{}, fmt.Errorf("CommentService - Insert: %w", err)
+		}
+		if len(parentsResp) == 0 {
+			return &dto.Comment{}, dto.NewCustomError(repo.ParentCommentNotFoundErr, newComment)
+		}
+		if parentsResp[0].ArticleID != newComment.ArticleID {
+			return &dto.Comment{}, dto.NewCustomError(repo.ParentCommentMismatchErr, newComment)
+		}
+		if parentsResp[0].Depth+1 > c.maxDepth {
+			return &dto.Comment{}, dto.NewCustomError(CommentDepthExceededErr, newComment)
+		}
+	}
+
 	logger.Debug("calling comment repo...")
 	commentResp, err := c.commentRepo.Insert(ctx, newComment)
 	if err != nil {
@@ -66,3 +123,76 @@ func (c *CommentService) Insert(ctx context.Context, newComment dto.NewComment)
 
 	return commentResp, nil
 }
+
+// Edit implements service.CommentService.
+func (c *CommentService) Edit(ctx context.Context, id int, text string) (*dto.Comment, error) {
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment repo...")
+	commentResp, err := c.commentRepo.Edit(ctx, id, text)
+	if err != nil {
+		return commentResp, fmt.Errorf("CommentService - Edit: %w", err)
+	}
+
+	return commentResp, nil
+}
+
+// GetRevisions implements service.CommentService.
+func (c *CommentService) GetRevisions(ctx context.Context, commentId int) ([]*dto.CommentRevision, error) {
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment repo...")
+	revisionsResp, err := c.commentRepo.GetRevisions(ctx, commentId)
+	if err != nil {
+		return revisionsResp, fmt.Errorf("CommentService - GetRevisions: %w", err)
+	}
+
+	return revisionsResp, nil
+}
+
+// Delete implements service.CommentService.
+func (c *CommentService) Delete(ctx context.Context, id int) (*dto.Comment, error) {
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment repo...")
+	commentResp, err := c.commentRepo.Delete(ctx, id)
+	if err != nil {
+		return commentResp, fmt.Errorf("CommentService - Delete: %w", err)
+	}
+
+	return commentResp, nil
+}
+
+// Count implements service.CommentService.
+func (c *CommentService) Count(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]int, error) {
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling comment repo...")
+	countsResp, err := c.commentRepo.Count(ctx, commentsReq...)
+	if err != nil {
+		return countsResp, fmt.Errorf("CommentService - Count: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return countsResp, nil
+}
+
+// Thread implements service.CommentService.
+func (c *CommentService) Thread(ctx context.Context, threadReq dto.ThreadRequest) ([]*dto.ThreadNode, error) {
+	logger := c.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	if (threadReq.CommentId == nil) == (threadReq.PostId == nil) {
+		return []*dto.ThreadNode{}, dto.NewCustomError(InvalidThreadRootErr, threadReq)
+	}
+	if threadReq.MaxDepth < 0 || threadReq.MaxNodes < 1 || threadReq.MaxNodes > MaxThreadNodes {
+		return []*dto.ThreadNode{}, dto.NewCustomError(InvalidThreadLimitErr, threadReq)
+	}
+
+	logger.Debug("calling comment repo...")
+	nodesResp, err := c.commentRepo.Thread(ctx, threadReq)
+	if err != nil {
+		return nodesResp, fmt.Errorf("CommentService - Thread: %w", err)
+	}
+
+	return nodesResp, nil
+}
@@ -2,6 +2,7 @@ package post
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
@@ -43,11 +44,11 @@ func (p *PostService) Get(ctx context.Context, id int) (*dto.Post, error) {
 }
 
 // GetMany implements service.PostService.
-func (p *PostService) GetMany(ctx context.Context, postsReq dto.GetPostsRequest) ([]*dto.Post, error) {
+func (p *PostService) GetMany(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]*dto.Post, error) {
 	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
 
 	logger.Debug("calling post repo...")
-	postResp, err := p.postRepo.GetMany(ctx, postsReq)
+	postResp, err := p.postRepo.GetMany(ctx, postsReq...)
 	if err != nil {
 		return postResp, fmt.Errorf("PostService - GetMany: %w", err)
 	}
@@ -69,3 +70,86 @@ func (p *PostService) Insert(ctx context.Context, newPost dto.NewPost) (*dto.Pos
 
 	return postResp, nil
 }
+
+// Update implements service.PostService.
+func (p *PostService) Update(ctx context.Context, id int, updatePost dto.UpdatePost) (*dto.Post, error) {
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	if updatePost.Title == nil && updatePost.Text == nil {
+		logger.Debug("nothing to update, calling post repo for current state...")
+		postResp, err := p.postRepo.Get(ctx, id)
+		if err != nil {
+			var cErr *dto.CustomError
+			if errors.As(err, &cErr) {
+				err = dto.NewCustomError(repo.PostNotFoundErr, id)
+			}
+			return postResp, fmt.Errorf("PostService - Update: %w", err)
+		}
+		return postResp, nil
+	}
+
+	logger.Debug("calling post repo...")
+	postResp, err := p.postRepo.Update(ctx, id, updatePost)
+	if err != nil {
+		return postResp, fmt.Errorf("PostService - Update: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return postResp, nil
+}
+
+// Delete implements service.PostService.
+func (p *PostService) Delete(ctx context.Context, id int) (*dto.Post, error) {
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling post repo...")
+	postResp, err := p.postRepo.Delete(ctx, id)
+	if err != nil {
+		return postResp, fmt.Errorf("PostService - Delete: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return postResp, nil
+}
+
+// Close implements service.PostService.
+func (p *PostService) Close(ctx context.Context, id int, closedBy int) (*dto.Post, error) {
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling post repo...")
+	postResp, err := p.postRepo.Close(ctx, id, closedBy)
+	if err != nil {
+		return postResp, fmt.Errorf("PostService - Close: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return postResp, nil
+}
+
+// Reopen implements service.PostService.
+func (p *PostService) Reopen(ctx context.Context, id int) (*dto.Post, error) {
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling post repo...")
+	postResp, err := p.postRepo.Reopen(ctx, id)
+	if err != nil {
+		return postResp, fmt.Errorf("PostService - Reopen: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return postResp, nil
+}
+
+// Count implements service.PostService.
+func (p *PostService) Count(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]int, error) {
+	logger := p.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling post repo...")
+	countsResp, err := p.postRepo.Count(ctx, postsReq...)
+	if err != nil {
+		return countsResp, fmt.Errorf("PostService - Count: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return countsResp, nil
+}
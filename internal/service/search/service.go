@@ -0,0 +1,107 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/middleware"
+	"github.com/elusiv0/oz_task/internal/repo"
+	"github.com/elusiv0/oz_task/internal/service"
+)
+
+// MaxSearchOffset caps the position of the search cursor: both repos rank everything up to
+// the requested page, so a crafted cursor would make them rank whole tables.
+const MaxSearchOffset = 1000
+
+var (
+	EmptySearchQueryErr = dto.ErrInfo{
+		ErrorMessage: "search query must not be empty",
+		StatusCode:   http.StatusBadRequest,
+	}
+	InvalidSearchCursorErr = dto.ErrInfo{
+		ErrorMessage: fmt.Sprintf("search cursor must be between 0 and %d", MaxSearchOffset),
+		StatusCode:   http.StatusBadRequest,
+	}
+	SearchResultsNotFoundErr = dto.ErrInfo{
+		ErrorMessage: "nothing was found by provided query",
+		StatusCode:   http.StatusNoContent,
+	}
+)
+
+type SearchService struct {
+	postRepo    repo.PostRepo
+	commentRepo repo.CommentRepo
+	logger      *slog.Logger
+}
+
+func New(
+	postRepo repo.PostRepo,
+	commentRepo repo.CommentRepo,
+	logger *slog.Logger,
+) *SearchService {
+	return &SearchService{
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		logger:      logger,
+	}
+}
+
+var _ service.SearchService = &SearchService{}
+
+// Search implements service.SearchService.
+// Posts and comments are ranked together, so the cursor is the position of the result
+// in the ranked list and both repos are asked for everything up to the requested page.
+func (s *SearchService) Search(ctx context.Context, searchReq dto.SearchRequest) ([]*dto.SearchResult, error) {
+	logger := s.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	if strings.TrimSpace(searchReq.Query) == "" {
+		return []*dto.SearchResult{}, dto.NewCustomError(EmptySearchQueryErr, searchReq)
+	}
+	offset := 0
+	if searchReq.After != nil {
+		if *searchReq.After < 0 || *searchReq.After > MaxSearchOffset {
+			return []*dto.SearchResult{}, dto.NewCustomError(InvalidSearchCursorErr, searchReq)
+		}
+		offset = *searchReq.After
+	}
+	limit := offset + searchReq.First + 1
+
+	logger.Debug("calling post repo...")
+	postsResp, err := s.postRepo.Search(ctx, searchReq.Query, limit)
+	if err != nil {
+		return []*dto.SearchResult{}, fmt.Errorf("SearchService - Search: %w", err)
+	}
+	logger.Debug("calling comment repo...")
+	commentsResp, err := s.commentRepo.Search(ctx, searchReq.Query, limit)
+	if err != nil {
+		return []*dto.SearchResult{}, fmt.Errorf("SearchService - Search: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	resultsResp := append(postsResp, commentsResp...)
+	sort.SliceStable(resultsResp, func(i, j int) bool {
+		if resultsResp[i].Score != resultsResp[j].Score {
+			return resultsResp[i].Score > resultsResp[j].Score
+		}
+		return createdAt(resultsResp[i]).After(createdAt(resultsResp[j]))
+	})
+	if offset >= len(resultsResp) {
+		return []*dto.SearchResult{}, dto.NewCustomError(SearchResultsNotFoundErr, searchReq)
+	}
+	resultsResp = resultsResp[offset:min(limit, len(resultsResp))]
+
+	return resultsResp, nil
+}
+
+func createdAt(result *dto.SearchResult) time.Time {
+	if result.Post != nil {
+		return result.Post.CreatedAt
+	}
+	return result.Comment.CreatedAt
+}
@@ -7,13 +7,35 @@ import (
 )
 
 type PostService interface {
-	GetMany(ctx context.Context, postsReq dto.GetPostsRequest) ([]*dto.Post, error)
+	GetMany(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]*dto.Post, error)
 	Insert(ctx context.Context, newPost dto.NewPost) (*dto.Post, error)
 	Get(ctx context.Context, id int) (*dto.Post, error)
+	Update(ctx context.Context, id int, updatePost dto.UpdatePost) (*dto.Post, error)
+	Delete(ctx context.Context, id int) (*dto.Post, error)
+	Close(ctx context.Context, id int, closedBy int) (*dto.Post, error)
+	Reopen(ctx context.Context, id int) (*dto.Post, error)
+	Count(ctx context.Context, postsReq ...dto.GetPostsRequest) ([]int, error)
 }
 
 type CommentService interface {
 	GetMany(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]*dto.Comment, error)
+	GetByIds(ctx context.Context, ids ...int) ([]*dto.Comment, error)
 	Insert(ctx context.Context, newComment dto.NewComment) (*dto.Comment, error)
 	Get(ctx context.Context, id int) (*dto.Comment, error)
+	Edit(ctx context.Context, id int, text string) (*dto.Comment, error)
+	Delete(ctx context.Context, id int) (*dto.Comment, error)
+	GetRevisions(ctx context.Context, commentId int) ([]*dto.CommentRevision, error)
+	Count(ctx context.Context, commentsReq ...dto.GetCommentsRequest) ([]int, error)
+	Thread(ctx context.Context, threadReq dto.ThreadRequest) ([]*dto.ThreadNode, error)
+}
+
+type UserService interface {
+	GetMany(ctx context.Context, usersReq dto.GetUsersRequest) ([]*dto.User, error)
+	GetByIds(ctx context.Context, ids ...int) ([]*dto.User, error)
+	Insert(ctx context.Context, newUser dto.NewUser) (*dto.User, error)
+	Get(ctx context.Context, id int) (*dto.User, error)
+}
+
+type SearchService interface {
+	Search(ctx context.Context, searchReq dto.SearchRequest) ([]*dto.SearchResult, error)
 }
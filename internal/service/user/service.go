@@ -0,0 +1,85 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/elusiv0/oz_task/internal/dto"
+	"github.com/elusiv0/oz_task/internal/middleware"
+	"github.com/elusiv0/oz_task/internal/repo"
+	"github.com/elusiv0/oz_task/internal/service"
+)
+
+type UserService struct {
+	userRepo repo.UserRepo
+	logger   *slog.Logger
+}
+
+func New(
+	userRepo repo.UserRepo,
+	logger *slog.Logger,
+) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+var _ service.UserService = &UserService{}
+
+// Get implements service.UserService.
+func (u *UserService) Get(ctx context.Context, id int) (*dto.User, error) {
+	logger := u.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user repo...")
+	userResp, err := u.userRepo.Get(ctx, id)
+	if err != nil {
+		return userResp, fmt.Errorf("UserService - Get: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return userResp, nil
+}
+
+// GetByIds implements service.UserService.
+func (u *UserService) GetByIds(ctx context.Context, ids ...int) ([]*dto.User, error) {
+	logger := u.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user repo...")
+	usersResp, err := u.userRepo.GetByIds(ctx, ids...)
+	if err != nil {
+		return usersResp, fmt.Errorf("UserService - GetByIds: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return usersResp, nil
+}
+
+// GetMany implements service.UserService.
+func (u *UserService) GetMany(ctx context.Context, usersReq dto.GetUsersRequest) ([]*dto.User, error) {
+	logger := u.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user repo...")
+	usersResp, err := u.userRepo.GetMany(ctx, usersReq)
+	if err != nil {
+		return usersResp, fmt.Errorf("UserService - GetMany: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return usersResp, nil
+}
+
+// Insert implements service.UserService.
+func (u *UserService) Insert(ctx context.Context, newUser dto.NewUser) (*dto.User, error) {
+	logger := u.logger.With(slog.String("request_id", middleware.GetUuid(ctx)))
+
+	logger.Debug("calling user repo...")
+	userResp, err := u.userRepo.Insert(ctx, newUser)
+	if err != nil {
+		return userResp, fmt.Errorf("UserService - Insert: %w", err)
+	}
+	logger.Debug("response was handled successfully")
+
+	return userResp, nil
+}
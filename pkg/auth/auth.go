@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+type Verifier struct {
+	alg       string
+	hmacKey   []byte
+	rsaKey    *rsa.PublicKey
+	issuer    string
+	audience  string
+	parseOpts []jwt.ParserOption
+}
+
+type Claims struct {
+	UserId int    `json:"-"`
+	Role   string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// New builds verifier for the provided algorithm,
+// key is a shared secret for HS256 and a PEM encoded public key for RS256.
+func New(alg string, key []byte, opts ...Option) (*Verifier, error) {
+	v := &Verifier{
+		alg: alg,
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	switch alg {
+	case HS256:
+		if len(key) == 0 {
+			return nil, fmt.Errorf("auth - New: empty secret for %s", alg)
+		}
+		v.hmacKey = key
+	case RS256:
+		rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(key)
+		if err != nil {
+			return nil, fmt.Errorf("auth - New - parse public key: %w", err)
+		}
+		v.rsaKey = rsaKey
+	default:
+		return nil, fmt.Errorf("auth - New: unsupported algorithm %q", alg)
+	}
+
+	v.parseOpts = []jwt.ParserOption{
+		jwt.WithValidMethods([]string{alg}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.issuer != "" {
+		v.parseOpts = append(v.parseOpts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		v.parseOpts = append(v.parseOpts, jwt.WithAudience(v.audience))
+	}
+
+	return v, nil
+}
+
+// Verify checks signature and registered claims of the token,
+// the subject claim has to hold the id of the user.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, v.keyFunc, v.parseOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userId, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: subject is not a user id", ErrInvalidToken)
+	}
+	claims.UserId = userId
+
+	return claims, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (any, error) {
+	if v.alg == HS256 {
+		return v.hmacKey, nil
+	}
+
+	return v.rsaKey, nil
+}
@@ -0,0 +1,15 @@
+package auth
+
+type Option func(v *Verifier)
+
+func Issuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+func Audience(audience string) Option {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
@@ -0,0 +1,76 @@
+package drain
+
+import (
+	"context"
+	"sync"
+)
+
+// Drain closes long-lived connections, such as websockets, on shutdown.
+// The http server doesn't wait for them since they are hijacked, so the drain tracks them itself.
+type Drain struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	active  int
+	changed chan struct{}
+}
+
+func New() *Drain {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Drain{
+		ctx:     ctx,
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+}
+
+// Track returns the context of the connection that is cancelled once the drain starts.
+// The connection is finished when the provided context is done.
+func (d *Drain) Track(ctx context.Context) context.Context {
+	d.mu.Lock()
+	d.active++
+	d.mu.Unlock()
+
+	tracked, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(d.ctx, cancel)
+	context.AfterFunc(ctx, func() {
+		stop()
+		cancel()
+		d.mu.Lock()
+		d.active--
+		close(d.changed)
+		d.changed = make(chan struct{})
+		d.mu.Unlock()
+	})
+
+	return tracked
+}
+
+// Start cancels contexts of the tracked connections.
+func (d *Drain) Start() {
+	d.cancel()
+}
+
+// Draining reports whether the drain was started.
+func (d *Drain) Draining() bool {
+	return d.ctx.Err() != nil
+}
+
+// Wait blocks until every tracked connection is finished or the context is done.
+func (d *Drain) Wait(ctx context.Context) error {
+	for {
+		d.mu.Lock()
+		active, changed := d.active, d.changed
+		d.mu.Unlock()
+		if active == 0 {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
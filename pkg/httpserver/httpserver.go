@@ -2,6 +2,7 @@ package httpserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -39,8 +40,9 @@ func New(h http.Handler, opts ...Option) *HttpServer {
 	return httpserver
 }
 
+// Start serves until the server fails or Shutdown is called, the latter is not an error.
 func (s *HttpServer) Start() error {
-	if err := s.server.ListenAndServe(); err != nil {
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("starting http server: %w", err)
 	}
 
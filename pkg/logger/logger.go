@@ -1,11 +1,15 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/lmittmann/tint"
 	"github.com/mattn/go-colorable"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 const (
@@ -14,37 +18,102 @@ const (
 	prod  = "prod"
 )
 
-func New(env string) (log *slog.Logger) {
+const (
+	FormatTint   = "tint"
+	FormatJson   = "json"
+	FormatLogfmt = "logfmt"
+
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+)
+
+type Logger struct {
+	level              slog.Level
+	format             string
+	output             string
+	maxSizeMB          int
+	maxBackups         int
+	maxAgeDays         int
+	samplingFirst      int
+	samplingThereafter int
+	samplingTick       time.Duration
+}
 
+// New builds the logger of the env, the env sets the defaults of the level and the format:
+// local is tint with debug, dev is json with debug and prod is json with info.
+func New(env string, opts ...Option) (*slog.Logger, error) {
+	l := &Logger{
+		output:       OutputStdout,
+		samplingTick: time.Second,
+	}
 	switch env {
 	case local:
-		log = slog.New(
-			tint.NewHandler(
-				colorable.NewColorable(os.Stdout),
-				&tint.Options{
-					Level: slog.LevelDebug,
-				},
-			),
-		)
+		l.level, l.format = slog.LevelDebug, FormatTint
 	case dev:
-		log = slog.New(
-			slog.NewJSONHandler(
-				os.Stdout,
-				&slog.HandlerOptions{
-					Level: slog.LevelDebug,
-				},
-			),
-		)
+		l.level, l.format = slog.LevelDebug, FormatJson
 	case prod:
-		log = slog.New(
-			slog.NewJSONHandler(
-				os.Stdout,
-				&slog.HandlerOptions{
-					Level: slog.LevelInfo,
-				},
-			),
-		)
+		l.level, l.format = slog.LevelInfo, FormatJson
+	default:
+		return nil, fmt.Errorf("Logger - New: unknown env %q", env)
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	handler, err := l.handler()
+	if err != nil {
+		return nil, fmt.Errorf("Logger - New: %w", err)
+	}
+	if l.samplingFirst > 0 {
+		handler = newSamplingHandler(handler, l.samplingFirst, l.samplingThereafter, l.samplingTick)
+	}
+
+	return slog.New(handler), nil
+}
+
+func (l *Logger) handler() (slog.Handler, error) {
+	var out io.Writer
+	switch l.output {
+	case OutputStdout:
+		out = os.Stdout
+	case OutputStderr:
+		out = os.Stderr
+	case "":
+		return nil, fmt.Errorf("empty output")
+	default:
+		out = &lumberjack.Logger{
+			Filename:   l.output,
+			MaxSize:    l.maxSizeMB,
+			MaxBackups: l.maxBackups,
+			MaxAge:     l.maxAgeDays,
+		}
+	}
+
+	switch l.format {
+	case FormatTint:
+		if file, ok := out.(*os.File); ok {
+			out = colorable.NewColorable(file)
+		}
+		return tint.NewHandler(out, &tint.Options{
+			Level: l.level,
+			// colors would litter a file
+			NoColor: l.output != OutputStdout && l.output != OutputStderr,
+		}), nil
+	case FormatJson:
+		return slog.NewJSONHandler(out, &slog.HandlerOptions{Level: l.level}), nil
+	case FormatLogfmt:
+		return slog.NewTextHandler(out, &slog.HandlerOptions{Level: l.level}), nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", l.format)
+}
+
+// ParseLevel parses names like debug and warn as well as offsets like debug-2.
+func ParseLevel(level string) (slog.Level, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return parsed, fmt.Errorf("Logger - ParseLevel: %w", err)
 	}
 
-	return
+	return parsed, nil
 }
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"log/slog"
+	"time"
+)
+
+type Option func(l *Logger)
+
+// Level overrides the level of the env.
+func Level(level slog.Level) Option {
+	return func(l *Logger) {
+		l.level = level
+	}
+}
+
+// Format overrides the format of the env, it is one of tint, json and logfmt.
+func Format(format string) Option {
+	return func(l *Logger) {
+		l.format = format
+	}
+}
+
+// Output is stdout, stderr or a path of the file rotated by size.
+func Output(output string) Option {
+	return func(l *Logger) {
+		l.output = output
+	}
+}
+
+func FileRotation(maxSizeMB, maxBackups, maxAgeDays int) Option {
+	return func(l *Logger) {
+		l.maxSizeMB = maxSizeMB
+		l.maxBackups = maxBackups
+		l.maxAgeDays = maxAgeDays
+	}
+}
+
+// Sampling lets through the first records of every debug message per tick and then every thereafter-th one,
+// zero first disables the sampling.
+func Sampling(first, thereafter int, tick time.Duration) Option {
+	return func(l *Logger) {
+		l.samplingFirst = first
+		l.samplingThereafter = thereafter
+		l.samplingTick = tick
+	}
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// samplingHandler thins out debug records, every repo and resolver step logs one,
+// so a busy instance would mostly write them. Records above debug are always kept.
+type samplingHandler struct {
+	next       slog.Handler
+	first      uint64
+	thereafter uint64
+	tick       time.Duration
+	// counters are shared with the handlers made by WithAttrs and WithGroup
+	counters *sync.Map
+}
+
+type counter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func newSamplingHandler(next slog.Handler, first, thereafter int, tick time.Duration) *samplingHandler {
+	return &samplingHandler{
+		next:       next,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		tick:       tick,
+		counters:   &sync.Map{},
+	}
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level > slog.LevelDebug || h.sample(record.Message, record.Time) {
+		return h.next.Handle(ctx, record)
+	}
+
+	return nil
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{
+		next:       h.next.WithAttrs(attrs),
+		first:      h.first,
+		thereafter: h.thereafter,
+		tick:       h.tick,
+		counters:   h.counters,
+	}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{
+		next:       h.next.WithGroup(name),
+		first:      h.first,
+		thereafter: h.thereafter,
+		tick:       h.tick,
+		counters:   h.counters,
+	}
+}
+
+// sample counts the message in the current tick and reports whether the record is kept.
+func (h *samplingHandler) sample(message string, now time.Time) bool {
+	value, _ := h.counters.LoadOrStore(message, &counter{})
+	c := value.(*counter)
+
+	var count uint64
+	if resetAt := c.resetAt.Load(); now.UnixNano() < resetAt {
+		count = c.count.Add(1)
+	} else {
+		c.count.Store(1)
+		c.resetAt.Store(now.Add(h.tick).UnixNano())
+		count = 1
+	}
+	if count <= h.first {
+		return true
+	}
+
+	return h.thereafter > 0 && (count-h.first)%h.thereafter == 0
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// the statements are traced here since pgx v4 has no tracer hooks,
+// every span is a child of the span in the provided context.
+var tracer = otel.Tracer("github.com/elusiv0/oz_task/pkg/postgres")
+
+// Querier is implemented by both the pool and a transaction.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (p *Postgres) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return exec(ctx, p.PgxPool, sql, args...)
+}
+
+func (p *Postgres) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return query(ctx, p.PgxPool, sql, args...)
+}
+
+func (p *Postgres) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return queryRow(ctx, p.PgxPool, sql, args...)
+}
+
+// Begin starts a transaction whose statements are traced the same way as the pool ones.
+func (p *Postgres) Begin(ctx context.Context) (*Tx, error) {
+	tx, err := p.PgxPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{tx: tx}, nil
+}
+
+type Tx struct {
+	tx pgx.Tx
+}
+
+func (t *Tx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return exec(ctx, t.tx, sql, args...)
+}
+
+func (t *Tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return query(ctx, t.tx, sql, args...)
+}
+
+func (t *Tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return queryRow(ctx, t.tx, sql, args...)
+}
+
+func (t *Tx) Commit(ctx context.Context) error {
+	return t.tx.Commit(ctx)
+}
+
+func (t *Tx) Rollback(ctx context.Context) error {
+	return t.tx.Rollback(ctx)
+}
+
+func exec(ctx context.Context, q Querier, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, span := startSpan(ctx, sql)
+	tag, err := q.Exec(ctx, sql, args...)
+	endSpan(span, err)
+
+	return tag, err
+}
+
+func query(ctx context.Context, q Querier, sql string, args ...any) (pgx.Rows, error) {
+	ctx, span := startSpan(ctx, sql)
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		endSpan(span, err)
+		return rows, err
+	}
+
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func queryRow(ctx context.Context, q Querier, sql string, args ...any) pgx.Row {
+	ctx, span := startSpan(ctx, sql)
+
+	return &tracedRow{row: q.QueryRow(ctx, sql, args...), span: span}
+}
+
+// tracedRows ends the span once the rows are read or closed.
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	endSpan(r.span, r.Rows.Err())
+
+	return false
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	endSpan(r.span, r.Rows.Err())
+}
+
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	endSpan(r.span, err)
+
+	return err
+}
+
+func startSpan(ctx context.Context, sql string) (context.Context, trace.Span) {
+	operation, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	operation = strings.ToUpper(operation)
+
+	return tracer.Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", sql),
+		),
+	)
+}
+
+// endSpan ends the span, an empty result is not an error of the statement.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	tests := []struct {
+		name      string
+		perMinute int
+		burst     int
+		calls     int
+		allowed   int
+	}{
+		{"within burst", 60, 3, 3, 3},
+		{"over burst", 60, 3, 5, 3},
+		{"single token", 60, 1, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := New(tt.perMinute, tt.burst)
+			allowed := 0
+			for range tt.calls {
+				if ok, _ := limiter.Allow("client"); ok {
+					allowed++
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed %d of %d calls, want %d", allowed, tt.calls, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestAllowRetryAfter(t *testing.T) {
+	// a token a second
+	limiter := New(60, 1)
+	if ok, retryAfter := limiter.Allow("client"); !ok || retryAfter != 0 {
+		t.Fatalf("first call: got %v, %s, want allowed", ok, retryAfter)
+	}
+	ok, retryAfter := limiter.Allow("client")
+	if ok {
+		t.Fatal("second call is allowed")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("retryAfter is %s, want up to a second", retryAfter)
+	}
+}
+
+func TestAllowKeys(t *testing.T) {
+	limiter := New(60, 1)
+	if ok, _ := limiter.Allow("first"); !ok {
+		t.Fatal("first key is rejected")
+	}
+	if ok, _ := limiter.Allow("second"); !ok {
+		t.Error("second key shares the bucket of the first one")
+	}
+}
+
+func TestAllowRefill(t *testing.T) {
+	// a token every 10ms
+	limiter := New(6000, 1)
+	if ok, _ := limiter.Allow("client"); !ok {
+		t.Fatal("first call is rejected")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if ok, _ := limiter.Allow("client"); !ok {
+		t.Error("bucket isn't refilled")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	limiter := New(6000, 1)
+	limiter.Allow("client")
+	time.Sleep(20 * time.Millisecond)
+	limiter.sweep(limiter.lastSweep.Add(sweepInterval))
+	if _, ok := limiter.buckets["client"]; ok {
+		t.Error("refilled bucket isn't dropped")
+	}
+}
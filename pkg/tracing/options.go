@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type Option func(t *Tracing)
+
+// Endpoint is the host:port of the OTLP/HTTP collector.
+func Endpoint(endpoint string) Option {
+	return func(t *Tracing) {
+		t.endpoint = endpoint
+	}
+}
+
+func Insecure(insecure bool) Option {
+	return func(t *Tracing) {
+		t.insecure = insecure
+	}
+}
+
+func SampleRatio(ratio float64) Option {
+	return func(t *Tracing) {
+		t.sampleRatio = ratio
+	}
+}
+
+func Environment(env string) Option {
+	return func(t *Tracing) {
+		t.env = env
+	}
+}
+
+// Exporter replaces the OTLP exporter, e.g. with tracetest.InMemoryExporter in tests.
+// The spans are exported synchronously then, so they are available right after End.
+func Exporter(exporter sdktrace.SpanExporter) Option {
+	return func(t *Tracing) {
+		t.exporter = exporter
+	}
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	serviceName = "oz_task"
+
+	defaultEndpoint    = "localhost:4318"
+	defaultSampleRatio = 1
+)
+
+// Tracing installs the global tracer provider, until it is built every tracer of the app is a noop.
+type Tracing struct {
+	endpoint    string
+	insecure    bool
+	sampleRatio float64
+	env         string
+	exporter    sdktrace.SpanExporter
+	provider    *sdktrace.TracerProvider
+}
+
+func New(ctx context.Context, opts ...Option) (*Tracing, error) {
+	t := &Tracing{
+		endpoint:    defaultEndpoint,
+		sampleRatio: defaultSampleRatio,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	res := resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("deployment.environment", t.env),
+	)
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.sampleRatio))),
+	}
+	if t.exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(t.exporter))
+	} else {
+		exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(t.endpoint)}
+		if t.insecure {
+			exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("Tracing - New: %w", err)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+	t.provider = sdktrace.NewTracerProvider(providerOpts...)
+
+	otel.SetTracerProvider(t.provider)
+
+	return t, nil
+}
+
+// Shutdown exports the buffered spans and stops the provider.
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	if err := t.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("Tracing - Shutdown: %w", err)
+	}
+
+	return nil
+}